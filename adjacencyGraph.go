@@ -0,0 +1,147 @@
+package kstar
+
+import "sort"
+
+// AdjacencyGraph is a Graph held in memory as the adjacency maps of nodes 0 to n-1, with optional heuristic values.
+// Nodes without a heuristic value have an FValue of 0. It also implements EdgeCoster and ReverseGraph.
+//
+// The maps returned by Connections and Incoming are the graph's own and must not be modified. Queries may share an AdjacencyGraph
+// as long as no edge or heuristic value is added meanwhile.
+type AdjacencyGraph struct {
+	s, t      int
+	edges     []map[int][]float64 // nil for nodes without outgoing edges
+	incoming  []map[int][]float64 // nil for nodes without incoming edges
+	nEdges    int
+	heuristic map[int]float64
+}
+
+// NewAdjacencyGraph returns a graph of n nodes without edges, departing from s and arriving at t.
+func NewAdjacencyGraph(n, s, t int) *AdjacencyGraph {
+	g := &AdjacencyGraph{
+		s:         s,
+		t:         t,
+		edges:     make([]map[int][]float64, n),
+		incoming:  make([]map[int][]float64, n),
+		heuristic: make(map[int]float64),
+	}
+	return g
+}
+
+// AddEdge adds an edge from u to v and returns its index among the edges from u to v.
+// The graph grows to include u and v if needed.
+func (g *AdjacencyGraph) AddEdge(u, v int, cost float64) (i int) {
+	for len(g.edges) <= u || len(g.edges) <= v {
+		g.edges = append(g.edges, nil)
+		g.incoming = append(g.incoming, nil)
+	}
+	if g.edges[u] == nil {
+		g.edges[u] = make(map[int][]float64)
+	}
+	if g.incoming[v] == nil {
+		g.incoming[v] = make(map[int][]float64)
+	}
+	g.edges[u][v] = append(g.edges[u][v], cost)
+	g.incoming[v][u] = append(g.incoming[v][u], cost)
+	g.nEdges++
+	return len(g.edges[u][v]) - 1
+}
+
+// SetFValue sets the heuristic cost from n to T().
+func (g *AdjacencyGraph) SetFValue(n int, h float64) {
+	g.heuristic[n] = h
+}
+
+// Heuristic returns the heuristic value of n and whether it was set.
+func (g *AdjacencyGraph) Heuristic(n int) (h float64, ok bool) {
+	h, ok = g.heuristic[n]
+	return
+}
+
+// Reroute returns a graph sharing g's edges that departs from s and arrives at t. The heuristic values are kept
+// only if t is g's arrival node, since they estimate the cost to it.
+func (g *AdjacencyGraph) Reroute(s, t int) *AdjacencyGraph {
+	rg := *g
+	rg.s, rg.t = s, t
+	if t != g.t {
+		rg.heuristic = make(map[int]float64)
+	}
+	return &rg
+}
+
+// Connections returns the costs of the edges from n to any other node.
+func (g *AdjacencyGraph) Connections(n int) map[int][]float64 {
+	if n < 0 || n >= len(g.edges) {
+		return nil
+	}
+	return g.edges[n]
+}
+
+// EdgeCost returns the cost of the ith edge from u to v.
+func (g *AdjacencyGraph) EdgeCost(u, v, i int) float64 {
+	return g.edges[u][v][i]
+}
+
+// S returns the departure node.
+func (g *AdjacencyGraph) S() int {
+	return g.s
+}
+
+// T returns the arrival node.
+func (g *AdjacencyGraph) T() int {
+	return g.t
+}
+
+// Incoming returns the costs of the edges from any other node to n.
+func (g *AdjacencyGraph) Incoming(n int) map[int][]float64 {
+	if n < 0 || n >= len(g.incoming) {
+		return nil
+	}
+	return g.incoming[n]
+}
+
+// FValue returns the heuristic cost from node n to T(), 0 if not set.
+func (g *AdjacencyGraph) FValue(n int) float64 {
+	return g.heuristic[n]
+}
+
+// NumNodes returns the number of nodes.
+func (g *AdjacencyGraph) NumNodes() int {
+	return len(g.edges)
+}
+
+// NumEdges returns the number of edges.
+func (g *AdjacencyGraph) NumEdges() int {
+	return g.nEdges
+}
+
+// Nodes returns the nodes of the graph in ascending order.
+func (g *AdjacencyGraph) Nodes() []int {
+	nodes := make([]int, len(g.edges))
+	for n := range nodes {
+		nodes[n] = n
+	}
+	return nodes
+}
+
+// Edges returns every edge of the graph sorted by U, V and I.
+func (g *AdjacencyGraph) Edges() []Edge {
+	edges := make([]Edge, 0, g.nEdges)
+	for u, conns := range g.edges {
+		for _, v := range sortedKeys(conns) {
+			for i := range conns[v] {
+				edges = append(edges, Edge{U: u, V: v, I: i})
+			}
+		}
+	}
+	return edges
+}
+
+// HeuristicNodes returns the nodes with a heuristic value set, in ascending order.
+func (g *AdjacencyGraph) HeuristicNodes() []int {
+	nodes := make([]int, 0, len(g.heuristic))
+	for n := range g.heuristic {
+		nodes = append(nodes, n)
+	}
+	sort.Ints(nodes)
+	return nodes
+}
@@ -2,17 +2,30 @@ package kstar
 
 import (
 	"container/heap"
+	"context"
+	"sort"
 )
 
 // astar keeps the needed structure for the K* astar algorithm. The algorithm does not assume a monotonic heuristic function is provided in g.
 type astar struct {
-	g Graph
+	g      Graph
+	coster EdgeCoster // nil if g does not implement EdgeCoster
 
 	pq                 []int
 	open               map[int]int // pq position, -1 if closed
 	gScore             map[int]float64
 	searchTreeParents  map[int]Edge
 	searchTreeChildren map[int]map[int]interface{}
+	costs              map[int]map[int][]float64 // Connections of every expanded node, unused if coster is set
+	reopenedNodes      int
+	obs                Observer        // nil if no observer is registered
+	forbidden          map[Edge]bool   // edges left out of the search
+	ctx                context.Context // interrupts run when done, nil if never
+	weight             float64         // factor of the heuristic in f values, 1 unless weighted
+	reaching           map[int]bool    // nodes that can reach T(), nil unless dead ends are pruned
+	prunedEdges        int
+	history            *treeHistory // changes of searchTreeParents, nil unless paths are returned as handles
+	changed            map[int]bool // nodes whose parent or g value changed since the last update of the path graph
 
 	c expansionConditionChecker
 }
@@ -23,10 +36,14 @@ func newAstar(g Graph) *astar {
 	var as astar
 
 	as.g = g
+	as.coster, _ = g.(EdgeCoster)
 	as.open = make(map[int]int, 0)
 	as.gScore = make(map[int]float64, 0)
 	as.searchTreeParents = make(map[int]Edge, 0)
 	as.searchTreeChildren = make(map[int]map[int]interface{}, 0)
+	as.costs = make(map[int]map[int][]float64, 0)
+	as.changed = make(map[int]bool)
+	as.weight = 1
 	arrivingEdges := make(map[int]int, 0)
 
 	initNode(g.S(), &as, arrivingEdges)
@@ -42,7 +59,7 @@ func newAstar(g Graph) *astar {
 func initNode(n int, as *astar, arrivingEdges map[int]int) {
 	as.open[n] = -1
 	as.gScore[n] = 0
-	as.searchTreeParents[n] = Edge{}
+	as.setParent(n, Edge{})
 	as.searchTreeChildren[n] = make(map[int]interface{}, 0)
 	arrivingEdges[n] = 0
 }
@@ -50,53 +67,74 @@ func initNode(n int, as *astar, arrivingEdges map[int]int) {
 func (as *astar) run() (newEdges []Edge, empty bool) {
 
 	newEdges = make([]Edge, 0)
+	if as.history != nil {
+		as.history.version++
+	}
 
 	for !as.Empty() {
 
-		if as.c.shouldStop(as.Top().(int), as.g.T()) {
+		top, topF := as.Top().(int), 0.0
+		if !as.c.start {
+			topF = as.fScore(top)
+		}
+		if as.c.shouldStop(top, as.g.T(), topF, as.gScore[as.g.T()]) {
+			return newEdges, false
+		}
+
+		if as.ctx != nil && as.c.expandedNodes%cancelCheckInterval == 0 && as.ctx.Err() != nil {
 			return newEdges, false
 		}
 
 		current := heap.Pop(as).(int)
 		reopening := as.c.expand(current)
+		if reopening {
+			as.reopenedNodes++
+		}
+		if as.obs != nil {
+			as.obs.NodeExpanded(current, as.gScore[current], reopening)
+		}
 
-		for neighbor, edges := range as.g.Connections(current) {
-
-			if _, ok := as.open[neighbor]; !ok {
-				initNode(neighbor, as, as.c.arrivingEdges)
+		conns := as.connections(current)
+		for _, neighbor := range sortedKeys(conns) {
+			if as.reaching != nil && !as.reaching[neighbor] {
+				// no path to T() goes through neighbor
+				as.prunedEdges += len(conns[neighbor])
+				continue
 			}
-
-			if len(edges) == 0 {
+			minEdge, minCost, allowed := as.processEdges(current, neighbor, conns[neighbor], &newEdges, reopening)
+			if allowed == 0 {
 				continue
 			}
 
-			as.c.hit(neighbor, len(edges))
-			minEdge, minCost := as.processEdges(current, neighbor, edges, &newEdges, reopening)
+			if _, ok := as.open[neighbor]; !ok {
+				initNode(neighbor, as, as.c.arrivingEdges)
+			}
+			as.c.hit(neighbor, allowed)
 
 			tentativeScore := as.gScore[current] + minCost
 			isOpen := as.open[neighbor] != -1
 			hasParent := as.searchTreeParents[neighbor] != Edge{}
 
 			e := Edge{current, neighbor, minEdge}
+			if neighbor == as.g.S() {
+				// S keeps its g value of 0, every edge arriving at it is a sidetrack
+				newEdges = appendIf(newEdges, &e, !reopening)
+				continue
+			}
+
 			if hasParent {
 				if tentativeScore >= as.gScore[neighbor] {
 					newEdges = appendIf(newEdges, &e, !reopening)
 					continue
 				}
+				// the replaced tree edge has never been reported, even if current is being reopened
 				parent := as.searchTreeParents[neighbor]
-				newEdges = appendIf(newEdges, &parent, !reopening)
+				newEdges = append(newEdges, parent)
+				delete(as.searchTreeChildren[parent.U], neighbor)
 			}
 
-			if neighbor == as.g.S() {
-				newEdges = appendIf(newEdges, &e, !reopening)
-			} else {
-				if hasParent {
-					oldParent := as.searchTreeParents[neighbor].U
-					delete(as.searchTreeChildren[oldParent], neighbor)
-				}
-				as.searchTreeParents[neighbor] = e
-				as.searchTreeChildren[current][neighbor] = true
-			}
+			as.setParent(neighbor, e)
+			as.searchTreeChildren[current][neighbor] = true
 
 			as.gScore[neighbor] = tentativeScore
 			if isOpen {
@@ -116,6 +154,18 @@ func (as *astar) run() (newEdges []Edge, empty bool) {
 
 }
 
+// cancelCheckInterval is the number of expansions between checks of the context of a query.
+const cancelCheckInterval = 64
+
+func sortedKeys(conns map[int][]float64) []int {
+	keys := make([]int, 0, len(conns))
+	for k := range conns {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
 func appendIf(newEdges []Edge, e *Edge, should bool) []Edge {
 	if should {
 		return append(newEdges, *e)
@@ -123,25 +173,47 @@ func appendIf(newEdges []Edge, e *Edge, should bool) []Edge {
 	return newEdges
 }
 
-func (as astar) processEdges(current, neighbor int, edges []float64, newEdges *[]Edge, reopening bool) (minEdge int, minCost float64) {
+// processEdges returns the cheapest of the edges from current to neighbor that are not forbidden, reporting the
+// others as sidetracks. allowed is the number of edges not forbidden.
+func (as astar) processEdges(current, neighbor int, edges []float64, newEdges *[]Edge, reopening bool) (minEdge int, minCost float64, allowed int) {
 
-	minCost, minEdge = edges[0], 0
-	e := 1
-	for _, cost := range edges[1:] {
-		if cost < minCost {
+	minEdge = -1
+	for e, cost := range edges {
+		if as.forbidden[Edge{current, neighbor, e}] {
+			continue
+		}
+		allowed++
+		switch {
+		case minEdge == -1:
+			minEdge, minCost = e, cost
+		case cost < minCost:
 			*newEdges = appendIf(*newEdges, &Edge{current, neighbor, minEdge}, !reopening)
 			minEdge, minCost = e, cost
-		} else {
+		default:
 			*newEdges = appendIf(*newEdges, &Edge{current, neighbor, e}, !reopening)
 		}
-		e++
 	}
 
 	return
 }
 
+// setParent makes e the edge arriving at n in the search tree.
+func (as *astar) setParent(n int, e Edge) {
+	as.searchTreeParents[n] = e
+	as.changed[n] = true
+	if as.history != nil {
+		as.history.set(n, e)
+	}
+}
+
+// closed reports whether n has been expanded and is not open again.
+func (as astar) closed(n int) bool {
+	pos, ok := as.open[n]
+	return ok && pos == -1
+}
+
 func (as astar) fScore(n int) float64 {
-	return as.gScore[n] + as.g.FValue(n)
+	return as.gScore[n] + as.weight*as.g.FValue(n)
 }
 
 func (as astar) minPathCost() (cost float64) {
@@ -152,7 +224,7 @@ func (as astar) minPathCost() (cost float64) {
 		if e == (Edge{}) {
 			break
 		}
-		cost += as.g.Connections(e.U)[node][e.I]
+		cost += as.edgeCost(e)
 		node = e.U
 	}
 
@@ -165,8 +237,31 @@ func (as astar) minPathCost() (cost float64) {
 }
 
 func (as astar) dValue(e Edge) float64 {
-	cost := as.g.Connections(e.U)[e.V][e.I]
-	return as.gScore[e.U] + cost - as.gScore[e.V]
+	return as.gScore[e.U] + as.edgeCost(e) - as.gScore[e.V]
+}
+
+// connections returns g.Connections(n), asking g only the first time n is expanded unless g is an EdgeCoster.
+// The map belongs to g and is only read.
+func (as *astar) connections(n int) map[int][]float64 {
+	if conns, ok := as.costs[n]; ok {
+		return conns
+	}
+	conns := as.g.Connections(n)
+	if as.coster == nil {
+		as.costs[n] = conns
+	}
+	return conns
+}
+
+// edgeCost returns the cost of e, either from g if it is an EdgeCoster or from the connections of the expanded e.U.
+func (as astar) edgeCost(e Edge) float64 {
+	if as.coster != nil {
+		return as.coster.EdgeCost(e.U, e.V, e.I)
+	}
+	if conns, ok := as.costs[e.U]; ok {
+		return conns[e.V][e.I]
+	}
+	return as.g.Connections(e.U)[e.V][e.I]
 }
 
 func (as astar) Len() int { return len(as.pq) }
@@ -204,9 +299,11 @@ type expansionConditionChecker struct {
 	innerEdges, expandedNodes       int
 	oldInnerEdges, oldExpandedNodes int
 	start                           bool
+	policy                          ResumptionPolicy // DoublingPolicy if nil
+	dijkstraKey                     float64          // key of the last path graph node popped by Dijkstra
 }
 
-func (c *expansionConditionChecker) shouldStop(top, t int) bool {
+func (c *expansionConditionChecker) shouldStop(top, t int, topF, optimalCost float64) bool {
 
 	stop := false
 
@@ -215,10 +312,20 @@ func (c *expansionConditionChecker) shouldStop(top, t int) bool {
 			c.start = false
 			stop = true
 		}
-	} else {
-		innerEdgesDoubled := c.oldInnerEdges == 0 && float32(c.innerEdges+2)/float32(c.oldInnerEdges+1) >= 2 || float32(c.innerEdges)/float32(c.oldInnerEdges) >= 2
-		expandedNodesDoubled := c.oldExpandedNodes == 0 && float32(c.expandedNodes+2)/float32(c.oldExpandedNodes+1) >= 2 || float32(c.expandedNodes)/float32(c.oldExpandedNodes) >= 2
-		stop = innerEdgesDoubled && expandedNodesDoubled
+	} else if c.expandedNodes > c.oldExpandedNodes {
+		policy := c.policy
+		if policy == nil {
+			policy = DoublingPolicy{}
+		}
+		stop = policy.ShouldStop(SearchProgress{
+			ExpandedNodes:         c.expandedNodes,
+			InnerEdges:            c.innerEdges,
+			ExpandedNodesAtResume: c.oldExpandedNodes,
+			InnerEdgesAtResume:    c.oldInnerEdges,
+			TopF:                  topF,
+			OptimalCost:           optimalCost,
+			DijkstraKey:           c.dijkstraKey,
+		})
 	}
 
 	if stop {
@@ -157,7 +157,7 @@ func TestProcessEdges(t *testing.T) {
 	g := newMockGraph(0, 1)
 	as := newAstar(g)
 
-	minEdge, minCost := as.processEdges(current, neighbor, edges, &newEdges, false)
+	minEdge, minCost, _ := as.processEdges(current, neighbor, edges, &newEdges, false)
 	expectedNewEdges := []Edge{Edge{0, 1, 0}, Edge{0, 1, 2}}
 	if minEdge != 1 || minCost != 0.1 {
 		t.Errorf("minimum edge not correctly processed. Expected %d (cost %f), but was %d (cost %f)", 1, 0.1, minEdge, minCost)
@@ -179,26 +179,81 @@ func TestExpansionConditionChecker(t *testing.T) {
 	c := expansionConditionChecker{
 		start: true,
 	}
-	if !c.shouldStop(1, 1) {
+	if !c.shouldStop(1, 1, 0, 0) {
 		t.Error("Should stop when finding t.")
 	}
 
 	c.start = false
 	c.innerEdges, c.oldInnerEdges = 12, 4
 	c.expandedNodes, c.oldExpandedNodes = 8, 3
-	if !c.shouldStop(1, 1) {
+	if !c.shouldStop(1, 1, 0, 0) {
 		t.Error("Should stop if expanded nodes and inner edges are doubled.")
 	}
 
 	c.innerEdges = 7
-	if c.shouldStop(1, 1) {
+	if c.shouldStop(1, 1, 0, 0) {
 		t.Error("Should not stop if inner edges is not doubled.")
 	}
 
 	c.innerEdges = 12
 	c.expandedNodes = 5
-	if c.shouldStop(1, 1) {
+	if c.shouldStop(1, 1, 0, 0) {
 		t.Error("Should not stop if expanded nodes is not doubles.")
 	}
 
 }
+
+type countingGraph struct {
+	mockGraph
+	calls map[int]int
+}
+
+func (g countingGraph) Connections(n int) map[int][]float64 {
+	g.calls[n]++
+	return g.mockGraph.Connections(n)
+}
+
+type costerGraph struct {
+	countingGraph
+}
+
+func (g costerGraph) EdgeCost(u, v, i int) float64 {
+	return g.graph[u][v][i]
+}
+
+func newDiamondGraph() mockGraph {
+	g := newMockGraph(0, 3)
+	g.graph[0] = map[int][]float64{1: {1, 3}, 2: {2}}
+	g.graph[1] = map[int][]float64{3: {2}}
+	g.graph[2] = map[int][]float64{3: {1, 4}}
+	g.graph[3] = map[int][]float64{}
+	return g
+}
+
+func TestConnectionsFetchedOnce(t *testing.T) {
+	g := countingGraph{mockGraph: newDiamondGraph(), calls: make(map[int]int)}
+	paths := Run(g, 10)
+	if len(paths) != 4 {
+		t.Errorf("Expected 4 paths, but found %d.", len(paths))
+	}
+	for n, calls := range g.calls {
+		if calls != 1 {
+			t.Errorf("Connections(%d) called %d times, expected once.", n, calls)
+		}
+	}
+}
+
+func TestEdgeCoster(t *testing.T) {
+	g := costerGraph{countingGraph{mockGraph: newDiamondGraph(), calls: make(map[int]int)}}
+	as := newAstar(g)
+	if as.coster == nil {
+		t.Fatal("EdgeCoster implementation not detected.")
+	}
+	as.run()
+	if d := as.dValue(Edge{0, 1, 1}); d != 2 {
+		t.Errorf("Expected d value 2, but found %f.", d)
+	}
+	if len(as.costs) != 0 {
+		t.Errorf("Connections cached although graph is an EdgeCoster.")
+	}
+}
@@ -0,0 +1,196 @@
+// Package batch runs many k shortest paths queries on one graph with a bounded pool of workers.
+//
+// Query files hold one query per line, either as three integers
+//
+//	<s> <t> <k>
+//
+// or as a JSON object with the fields of Query. Blank lines and lines starting with # are ignored.
+package batch
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jcasado94/kstar"
+	"github.com/jcasado94/kstar/graphio"
+)
+
+// Query asks for the k shortest paths from S to T.
+type Query struct {
+	S int `json:"s"`
+	T int `json:"t"`
+	K int `json:"k"`
+	kstar.QueryOptions
+
+	// Line is the line of the query in its file, 0 if it was not read from one.
+	Line int `json:"-"`
+}
+
+// Result is the answer to a query of a batch, Err being set if it failed.
+type Result struct {
+	Query  Query
+	Result *kstar.Result
+	Err    error
+}
+
+// MarshalJSON writes r as an object with the line of the query, its result and its error.
+func (r Result) MarshalJSON() ([]byte, error) {
+	out := struct {
+		Line   int           `json:"line,omitempty"`
+		Result *kstar.Result `json:"result,omitempty"`
+		Error  string        `json:"error,omitempty"`
+	}{Line: r.Query.Line, Result: r.Result}
+	if r.Err != nil {
+		out.Error = r.Err.Error()
+	}
+	return json.Marshal(out)
+}
+
+// ReadQueries reads a query file. Malformed lines are reported as *graphio.ParseError.
+func ReadQueries(r io.Reader) ([]Query, error) {
+	var queries []Query
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		q, err := parseQuery(text)
+		if err != nil {
+			return nil, &graphio.ParseError{Line: line, Err: err}
+		}
+		q.Line = line
+		queries = append(queries, q)
+	}
+	return queries, scanner.Err()
+}
+
+func parseQuery(text string) (q Query, err error) {
+	if strings.HasPrefix(text, "{") {
+		dec := json.NewDecoder(strings.NewReader(text))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&q)
+		return
+	}
+	vals := strings.Fields(text)
+	if len(vals) != 3 {
+		return q, fmt.Errorf("expected s t k, found %d values", len(vals))
+	}
+	ints := make([]int, len(vals))
+	for i, val := range vals {
+		if ints[i], err = strconv.Atoi(val); err != nil {
+			return q, err
+		}
+	}
+	q.S, q.T, q.K = ints[0], ints[1], ints[2]
+	return q, nil
+}
+
+// Runner runs batches of queries.
+type Runner struct {
+	// Workers is the number of queries run at the same time, GOMAXPROCS if not positive.
+	Workers int
+	// Timeout is the longest a single query may run, no limit if 0.
+	Timeout time.Duration
+	// GraphID identifies the graph in the results.
+	GraphID string
+}
+
+// Run answers queries on g, which is shared by the workers and must not be modified meanwhile. The results are in
+// the order of queries. Once ctx is done, the queries not yet answered fail with its error.
+func (rn Runner) Run(ctx context.Context, g *kstar.AdjacencyGraph, queries []Query) []Result {
+	results := make([]Result, len(queries))
+	rn.Stream(ctx, g, queries, func(i int, res Result) {
+		results[i] = res
+	})
+	return results
+}
+
+// Stream answers queries on g like Run, calling emit with the result of every query as soon as it and all the ones
+// before it are answered, so that results are emitted in the order of queries. emit is never called concurrently.
+func (rn Runner) Stream(ctx context.Context, g *kstar.AdjacencyGraph, queries []Query, emit func(i int, res Result)) {
+	workers := rn.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	jobs := make(chan int)
+	done := make(chan indexedResult)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				done <- indexedResult{i, rn.answer(ctx, g, queries[i])}
+			}
+		}()
+	}
+	go func() {
+		for i := range queries {
+			jobs <- i
+		}
+		close(jobs)
+		wg.Wait()
+		close(done)
+	}()
+
+	// results arriving ahead of their turn wait here
+	pending := make(map[int]Result)
+	next := 0
+	for ir := range done {
+		pending[ir.i] = ir.res
+		for res, ok := pending[next]; ok; res, ok = pending[next] {
+			delete(pending, next)
+			emit(next, res)
+			next++
+		}
+	}
+}
+
+type indexedResult struct {
+	i   int
+	res Result
+}
+
+func (rn Runner) answer(ctx context.Context, g *kstar.AdjacencyGraph, q Query) Result {
+	res := Result{Query: q}
+	if err := ctx.Err(); err != nil {
+		res.Err = err
+		return res
+	}
+	if q.K < 1 {
+		res.Err = errors.New("k must be positive")
+		return res
+	}
+	inGraph := func(n int) bool {
+		return n >= 0 && n < g.NumNodes()
+	}
+	if !inGraph(q.S) || !inGraph(q.T) {
+		res.Err = fmt.Errorf("s %d or t %d out of range [0, %d)", q.S, q.T, g.NumNodes())
+		return res
+	}
+
+	if rn.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, rn.Timeout)
+		defer cancel()
+	}
+	rg := g.Reroute(q.S, q.T)
+	paths, stats, err := kstar.RunContext(ctx, rg, q.K, q.Options(q.K)...)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	res.Result = kstar.NewResult(rn.GraphID, rg, q.K, paths, &stats)
+	return res
+}
@@ -0,0 +1,86 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jcasado94/kstar"
+	"github.com/jcasado94/kstar/graphio"
+)
+
+func TestReadQueries(t *testing.T) {
+	input := `# s t k
+0 4 3
+
+{"s": 1, "t": 4, "k": 2, "loopless": true, "cost_bound": 6}
+`
+	queries, err := ReadQueries(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(queries) != 2 || queries[0].S != 0 || queries[0].K != 3 || queries[0].Line != 2 {
+		t.Fatalf("Unexpected queries %+v.", queries)
+	}
+	if q := queries[1]; q.S != 1 || q.T != 4 || !q.Loopless || q.CostBound == nil || *q.CostBound != 6 || q.Line != 4 {
+		t.Errorf("Unexpected JSON query %+v.", q)
+	}
+
+	for input, line := range map[string]int{
+		"0 4 3\n0 4\n":                 2,
+		"0 4 x\n":                      1,
+		"\n{\"s\": 0, \"x\": 1}\n":     2,
+		"0 4 3\n{\"s\": 0, \"t\": 4\n": 2,
+	} {
+		_, err := ReadQueries(strings.NewReader(input))
+		var pe *graphio.ParseError
+		if !errors.As(err, &pe) || pe.Line != line {
+			t.Errorf("Expected an error at line %d reading %q, but found %v.", line, input, err)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	g, err := graphio.ReadFile("../datasets/graph/5.2.graph")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var queries []Query
+	for s := 0; s < g.NumNodes(); s++ {
+		for k := 1; k <= 10; k++ {
+			queries = append(queries, Query{S: s, T: g.T(), K: k})
+		}
+	}
+	queries = append(queries, Query{S: 0, T: 99, K: 1}, Query{S: 0, T: 4, K: 0})
+
+	results := Runner{Workers: 4}.Run(context.Background(), g, queries)
+	if len(results) != len(queries) {
+		t.Fatalf("Expected %d results, but found %d.", len(queries), len(results))
+	}
+	for i, res := range results[:len(results)-2] {
+		q := queries[i]
+		if res.Err != nil || !reflect.DeepEqual(res.Query, q) {
+			t.Errorf("Query %+v failed: %v", q, res.Err)
+			continue
+		}
+		paths := kstar.Run(g.Reroute(q.S, q.T), q.K)
+		if !reflect.DeepEqual(res.Result.KstarPaths(), paths) {
+			t.Errorf("Query %+v returned\n%v, but expected\n%v", q, res.Result.KstarPaths(), paths)
+		}
+	}
+	for _, res := range results[len(results)-2:] {
+		if res.Err == nil {
+			t.Errorf("Expected an error for query %+v.", res.Query)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, res := range (Runner{}).Run(ctx, g, queries[:3]) {
+		if res.Err != context.Canceled {
+			t.Errorf("Expected a cancelled query, but found %v.", res.Err)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package kstar
+
+import (
+	"container/list"
+	"sync"
+)
+
+// CachedGraph is a Graph adapter that memoizes Connections and FValue of the wrapped Graph in LRU caches.
+// It is meant for implicit graphs whose Connections are expensive to generate.
+//
+// CachedGraph implements EdgeCoster, so K* does not keep its own references to the connections of every expanded node
+// and memory stays bounded by the cache size. Unless the wrapped Graph is an EdgeCoster, the costs asked for are
+// memoized too, so that a cost read after its node was evicted does not generate its connections again.
+// If the wrapped Graph is a ReverseGraph, so is CachedGraph, memoizing Incoming too; K* does not treat it as one
+// otherwise.
+// It is safe for concurrent use by several queries as long as the wrapped Graph is; two goroutines missing the same
+// node at once may both call the wrapped Graph.
+type CachedGraph struct {
+	g       Graph
+	coster  EdgeCoster   // nil if g does not implement EdgeCoster
+	reverse ReverseGraph // nil if g does not implement ReverseGraph
+
+	mu          sync.Mutex
+	connections *lru
+	incoming    *lru
+	fValues     *lru
+	costs       *lru // by Edge, unused if coster is set
+	stats       CacheStats
+}
+
+// CacheStats holds the hit and miss counters of a CachedGraph.
+type CacheStats struct {
+	ConnectionsHits, ConnectionsMisses int
+	IncomingHits, IncomingMisses       int
+	FValueHits, FValueMisses           int
+	CostHits, CostMisses               int
+	Evictions                          int
+}
+
+// NewCachedGraph wraps g memoizing up to size nodes for Connections, Incoming and FValue, and up to size edges for
+// EdgeCost.
+// A non-positive size means unbounded.
+func NewCachedGraph(g Graph, size int) *CachedGraph {
+	cg := &CachedGraph{
+		g:           g,
+		connections: newLru(size),
+		incoming:    newLru(size),
+		fValues:     newLru(size),
+		costs:       newLru(size),
+	}
+	cg.coster, _ = g.(EdgeCoster)
+	cg.reverse, _ = g.(ReverseGraph)
+	return cg
+}
+
+// Connections returns the memoized connections of n, asking the wrapped Graph on a miss.
+func (cg *CachedGraph) Connections(n int) map[int][]float64 {
+	cg.mu.Lock()
+	conns, ok := cg.connections.get(n)
+	if ok {
+		cg.stats.ConnectionsHits++
+		cg.mu.Unlock()
+		return conns.(map[int][]float64)
+	}
+	cg.stats.ConnectionsMisses++
+	cg.mu.Unlock()
+
+	conns = cg.g.Connections(n)
+
+	cg.mu.Lock()
+	cg.stats.Evictions += cg.connections.put(n, conns)
+	cg.mu.Unlock()
+	return conns.(map[int][]float64)
+}
+
+// Incoming returns the memoized incoming edges of n, asking the wrapped Graph on a miss. It returns nil if the wrapped
+// Graph is not a ReverseGraph.
+func (cg *CachedGraph) Incoming(n int) map[int][]float64 {
+	if cg.reverse == nil {
+		return nil
+	}
+	cg.mu.Lock()
+	conns, ok := cg.incoming.get(n)
+	if ok {
+		cg.stats.IncomingHits++
+		cg.mu.Unlock()
+		return conns.(map[int][]float64)
+	}
+	cg.stats.IncomingMisses++
+	cg.mu.Unlock()
+
+	conns = cg.reverse.Incoming(n)
+
+	cg.mu.Lock()
+	cg.stats.Evictions += cg.incoming.put(n, conns)
+	cg.mu.Unlock()
+	return conns.(map[int][]float64)
+}
+
+// EdgeCost returns the cost of the ith edge from u to v, from the wrapped Graph if it is an EdgeCoster or memoized
+// from the connections of u otherwise.
+func (cg *CachedGraph) EdgeCost(u, v, i int) float64 {
+	if cg.coster != nil {
+		return cg.coster.EdgeCost(u, v, i)
+	}
+	e := Edge{U: u, V: v, I: i}
+	cg.mu.Lock()
+	cost, ok := cg.costs.get(e)
+	if ok {
+		cg.stats.CostHits++
+		cg.mu.Unlock()
+		return cost.(float64)
+	}
+	cg.stats.CostMisses++
+	cg.mu.Unlock()
+
+	cost = cg.Connections(u)[v][i]
+
+	cg.mu.Lock()
+	cg.stats.Evictions += cg.costs.put(e, cost)
+	cg.mu.Unlock()
+	return cost.(float64)
+}
+
+// S returns the departure node of the wrapped Graph.
+func (cg *CachedGraph) S() int {
+	return cg.g.S()
+}
+
+// T returns the arrival node of the wrapped Graph.
+func (cg *CachedGraph) T() int {
+	return cg.g.T()
+}
+
+// FValue returns the memoized heuristic cost from n to T(), asking the wrapped Graph on a miss.
+func (cg *CachedGraph) FValue(n int) float64 {
+	cg.mu.Lock()
+	f, ok := cg.fValues.get(n)
+	if ok {
+		cg.stats.FValueHits++
+		cg.mu.Unlock()
+		return f.(float64)
+	}
+	cg.stats.FValueMisses++
+	cg.mu.Unlock()
+
+	f = cg.g.FValue(n)
+
+	cg.mu.Lock()
+	cg.stats.Evictions += cg.fValues.put(n, f)
+	cg.mu.Unlock()
+	return f.(float64)
+}
+
+// Stats returns a snapshot of the cache counters.
+func (cg *CachedGraph) Stats() CacheStats {
+	cg.mu.Lock()
+	defer cg.mu.Unlock()
+	return cg.stats
+}
+
+// lru is a least recently used cache of the values of nodes or edges. It is not safe for concurrent use.
+type lru struct {
+	size  int
+	order *list.List // front is the most recently used
+	items map[interface{}]*list.Element
+}
+
+type lruEntry struct {
+	key   interface{}
+	value interface{}
+}
+
+func newLru(size int) *lru {
+	return &lru{
+		size:  size,
+		order: list.New(),
+		items: make(map[interface{}]*list.Element),
+	}
+}
+
+func (c *lru) get(key interface{}) (value interface{}, ok bool) {
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*lruEntry).value, true
+}
+
+// put stores value for key, returning the number of evicted entries.
+func (c *lru) put(key, value interface{}) (evicted int) {
+	if elem, ok := c.items[key]; ok {
+		elem.Value.(*lruEntry).value = value
+		c.order.MoveToFront(elem)
+		return 0
+	}
+	c.items[key] = c.order.PushFront(&lruEntry{key: key, value: value})
+	for c.size > 0 && c.order.Len() > c.size {
+		last := c.order.Back()
+		c.order.Remove(last)
+		delete(c.items, last.Value.(*lruEntry).key)
+		evicted++
+	}
+	return evicted
+}
@@ -0,0 +1,90 @@
+package kstar
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestCachedGraph(t *testing.T) {
+	g := countingGraph{mockGraph: newDiamondGraph(), calls: make(map[int]int)}
+	cg := NewCachedGraph(g, 2)
+
+	cg.Connections(0)
+	cg.Connections(0)
+	cg.Connections(1)
+	cg.Connections(2) // evicts 0
+	cg.Connections(0)
+
+	stats := cg.Stats()
+	if stats.ConnectionsHits != 1 || stats.ConnectionsMisses != 4 {
+		t.Errorf("Expected 1 hit and 4 misses, but found %d hits and %d misses.", stats.ConnectionsHits, stats.ConnectionsMisses)
+	}
+	if stats.Evictions != 2 {
+		t.Errorf("Expected 2 evictions, but found %d.", stats.Evictions)
+	}
+	if g.calls[0] != 2 {
+		t.Errorf("Connections(0) called %d times on the wrapped graph, expected twice.", g.calls[0])
+	}
+
+	cg.FValue(3)
+	cg.FValue(3)
+	if stats = cg.Stats(); stats.FValueHits != 1 || stats.FValueMisses != 1 {
+		t.Errorf("Expected 1 FValue hit and 1 miss, but found %d hits and %d misses.", stats.FValueHits, stats.FValueMisses)
+	}
+}
+
+func TestCachedGraphConcurrentRuns(t *testing.T) {
+	cg := NewCachedGraph(newDiamondGraph(), 0)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if paths := Run(cg, 10); len(paths) != 4 {
+				t.Errorf("Expected 4 paths, but found %d.", len(paths))
+			}
+		}()
+	}
+	wg.Wait()
+	if stats := cg.Stats(); stats.ConnectionsMisses < 4 || stats.ConnectionsHits == 0 {
+		t.Errorf("Unexpected cache counters %+v.", stats)
+	}
+}
+
+func TestCachedGraphEdgeCost(t *testing.T) {
+	g := countingGraph{mockGraph: newDiamondGraph(), calls: make(map[int]int)}
+	cg := NewCachedGraph(g, 1)
+
+	if cost := cg.EdgeCost(0, 1, 1); cost != 3 {
+		t.Errorf("Expected a cost of 3, but found %f.", cost)
+	}
+	cg.Connections(2) // evicts 0
+	cg.EdgeCost(0, 1, 1)
+	if g.calls[0] != 1 {
+		t.Errorf("Connections(0) called %d times on the wrapped graph, expected once.", g.calls[0])
+	}
+	if stats := cg.Stats(); stats.CostHits != 1 || stats.CostMisses != 1 {
+		t.Errorf("Expected 1 cost hit and 1 miss, but found %d hits and %d misses.", stats.CostHits, stats.CostMisses)
+	}
+}
+
+func TestCachedGraphIncoming(t *testing.T) {
+	g := NewAdjacencyGraph(3, 0, 2)
+	g.AddEdge(0, 2, 1)
+	g.AddEdge(1, 2, 2)
+	cg := NewCachedGraph(g, 0)
+
+	cg.Incoming(2)
+	if incoming := cg.Incoming(2); len(incoming) != 2 || incoming[1][0] != 2 {
+		t.Errorf("Expected the edges from 0 and 1, but found %v.", incoming)
+	}
+	if stats := cg.Stats(); stats.IncomingHits != 1 || stats.IncomingMisses != 1 {
+		t.Errorf("Expected 1 Incoming hit and 1 miss, but found %d hits and %d misses.", stats.IncomingHits, stats.IncomingMisses)
+	}
+
+	// caches of graphs without incoming edges are not reverse graphs, even wrapped
+	cached := NewCachedGraph(newDiamondGraph(), 0)
+	if _, ok := asReverseGraph(WrapGraph(fValueWrapper{cached}, cached)); ok {
+		t.Error("Expected a cached graph without incoming edges not to be a ReverseGraph.")
+	}
+}
@@ -0,0 +1,91 @@
+package kstar
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// snapshot returns a deep copy of the connections of the nodes reachable from S().
+func snapshot(g Graph) map[int]map[int][]float64 {
+	conns := make(map[int]map[int][]float64)
+	queue := []int{g.S()}
+	conns[g.S()] = nil
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		cp := make(map[int][]float64)
+		for v, costs := range g.Connections(n) {
+			cp[v] = append([]float64(nil), costs...)
+			if _, ok := conns[v]; !ok {
+				conns[v] = nil
+				queue = append(queue, v)
+			}
+		}
+		conns[n] = cp
+	}
+	return conns
+}
+
+// newGridGraph returns a w by h grid with edges in both directions between neighbouring nodes and h=0,
+// going from one corner to the opposite one.
+func newGridGraph(w, h int) *AdjacencyGraph {
+	g := NewAdjacencyGraph(w*h, 0, w*h-1)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			n := y*w + x
+			if x+1 < w {
+				g.AddEdge(n, n+1, float64(1+(x*y)%3))
+				g.AddEdge(n+1, n, float64(1+(x+y)%2))
+			}
+			if y+1 < h {
+				g.AddEdge(n, n+w, float64(1+(x+y)%3))
+				g.AddEdge(n+w, n, 2)
+			}
+		}
+	}
+	return g
+}
+
+func TestQueriesDoNotModifyGraph(t *testing.T) {
+	for _, tg := range generateTests() {
+		before := snapshot(tg.tg)
+		Run(tg.tg, tg.k)
+		RunWithOptions(tg.tg, tg.k, WithLoopless(10*tg.k), WithForbiddenEdges(Edge{U: tg.tg.S(), V: 1, I: 0}))
+		if !reflect.DeepEqual(before, snapshot(tg.tg)) {
+			t.Errorf("Test %s failed! The graph was modified by the queries.", tg.tg.TestName)
+		}
+	}
+}
+
+// TestConcurrentRuns runs queries in parallel on shared graphs. Run it with -race to detect data races.
+func TestConcurrentRuns(t *testing.T) {
+	grid := newGridGraph(12, 12)
+	type query struct {
+		name string
+		g    Graph
+		k    int
+	}
+	queries := []query{
+		{"grid", grid, 50},
+		{"cached grid", NewCachedGraph(grid, 16), 50},
+	}
+	for _, tg := range generateTests() {
+		queries = append(queries, query{tg.tg.TestName, tg.tg, tg.k})
+	}
+
+	for _, q := range queries {
+		expected := Run(q.g, q.k)
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if paths := Run(q.g, q.k); !reflect.DeepEqual(paths, expected) {
+					t.Errorf("Test %s failed! A concurrent query returned different paths.", q.name)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+}
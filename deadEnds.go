@@ -0,0 +1,23 @@
+package kstar
+
+import "errors"
+
+// ErrNotReverseGraph is the error of queries with WithDeadEndPruning on a Graph that is not a ReverseGraph.
+var ErrNotReverseGraph = errors.New("dead end pruning needs a ReverseGraph")
+
+// reachingNodes returns the nodes that can reach g.T(), found by a breadth-first search along the incoming edges.
+func reachingNodes(g ReverseGraph) map[int]bool {
+	reaching := map[int]bool{g.T(): true}
+	queue := []int{g.T()}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for u := range g.Incoming(v) {
+			if !reaching[u] {
+				reaching[u] = true
+				queue = append(queue, u)
+			}
+		}
+	}
+	return reaching
+}
@@ -0,0 +1,58 @@
+package kstar
+
+import (
+	"context"
+	"testing"
+)
+
+// newDeadEndGraph returns a grid whose departure node also leads to a cycle of nodes that cannot reach T.
+func newDeadEndGraph() *AdjacencyGraph {
+	g := newGridGraph(4, 4)
+	n := g.NumNodes()
+	g.AddEdge(g.S(), n, 1)
+	for i := 0; i < 10; i++ {
+		g.AddEdge(n+i, n+i+1, 1)
+		g.AddEdge(n+i+1, n+i, 1)
+	}
+	return g
+}
+
+func TestDeadEndPruning(t *testing.T) {
+	g := newDeadEndGraph()
+	paths, stats := RunWithOptions(g, 10)
+	prunedPaths, prunedStats := RunWithOptions(g, 10, WithDeadEndPruning())
+	if len(paths) != len(prunedPaths) {
+		t.Fatalf("Expected %d paths with pruning, but found %d.", len(paths), len(prunedPaths))
+	}
+	for i := range paths {
+		if pathCost(g, paths[i]) != pathCost(g, prunedPaths[i]) {
+			t.Errorf("Path %d costs %f with pruning, but expected %f.", i, pathCost(g, prunedPaths[i]), pathCost(g, paths[i]))
+		}
+	}
+	if prunedStats.PrunedEdges != 1 || prunedStats.ExpandedNodes >= stats.ExpandedNodes {
+		t.Errorf("Expected 1 pruned edge and fewer than %d expansions, but found %+v.", stats.ExpandedNodes, prunedStats)
+	}
+
+	// from the cycle T cannot be reached
+	noPath := g.Reroute(g.NumNodes()-1, g.T())
+	paths, stats = RunWithOptions(noPath, 10, WithDeadEndPruning())
+	if len(paths) != 0 || stats.ExpandedNodes != 0 {
+		t.Errorf("Expected no paths and no expansions, but found %d paths and %d expansions.", len(paths), stats.ExpandedNodes)
+	}
+
+	// wrappers keep the incoming edges of the graph they wrap
+	if _, stats := RunWithOptions(WrapGraph(fValueWrapper{g}, g), 10, WithDeadEndPruning()); stats.PrunedEdges != 1 {
+		t.Errorf("Expected 1 pruned edge through a wrapper, but found %d.", stats.PrunedEdges)
+	}
+
+	// and so do caches
+	cg := NewCachedGraph(g, 10)
+	if _, stats := RunWithOptions(cg, 10, WithDeadEndPruning()); stats.PrunedEdges != 1 || cg.Stats().IncomingMisses == 0 {
+		t.Errorf("Expected 1 pruned edge through a cache asking for incoming edges, but found %d and %+v.", stats.PrunedEdges, cg.Stats())
+	}
+
+	// graphs without incoming edges cannot be pruned
+	if _, _, err := RunContext(context.Background(), newDiamondGraph(), 10, WithDeadEndPruning()); err != ErrNotReverseGraph {
+		t.Errorf("Expected ErrNotReverseGraph, but found %v.", err)
+	}
+}
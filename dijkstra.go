@@ -5,15 +5,15 @@ import (
 )
 
 type dijkstra struct {
-	pq        []*dijkstraNode
-	formerTop *dijkstraNode
+	pq   []*dijkstraNode
+	pops int
+	obs  Observer // nil if no observer is registered
 }
 
 func newDijkstra(rn *rNode) (d *dijkstra) {
 	r := newDijkstraNode(rn, 0, []*dijkstraNode{}, false, true)
 	d = &dijkstra{
-		pq:        []*dijkstraNode{r},
-		formerTop: nil,
+		pq: []*dijkstraNode{r},
 	}
 	heap.Init(d)
 	return
@@ -42,39 +42,30 @@ func newDijkstraNode(n pathGraphNode, cost float64, path []*dijkstraNode, isCros
 	return &dn
 }
 
-func (d *dijkstra) step() (path []*dijkstraNode, empty bool) {
+func (d *dijkstra) step() (path []*dijkstraNode) {
 
-	path = make([]*dijkstraNode, 0)
 	current := heap.Pop(d).(*dijkstraNode)
+	d.pops++
+	if d.obs != nil {
+		u, v, i := current.n.EdgeKeys()
+		d.obs.PathGraphNodePopped(Edge{U: u, V: v, I: i}, current.cost)
+	}
 
 	d.pushChildren(current)
 
-	if d.Empty() {
-		d.formerTop = current
-		return current.path, true
-	}
-
-	return current.path, false
+	return current.path
 
 }
 
-func (d *dijkstra) pushChildren(current *dijkstraNode) (hasChildren bool) {
+func (d *dijkstra) pushChildren(current *dijkstraNode) {
 	c := current.n.CrossEdgeChild()
 	if c != nil {
 		heap.Push(d, newDijkstraNode(c, current.cost+c.D(), current.path, true, false))
-		hasChildren = true
 	}
 
 	for _, c := range current.n.HeapEdgeChildren() {
 		heap.Push(d, newDijkstraNode(c, current.cost+c.D()-current.n.D(), current.path, false, false))
-		hasChildren = true
 	}
-
-	return hasChildren
-}
-
-func (d *dijkstra) resume() (end bool) {
-	return !d.pushChildren(d.formerTop)
 }
 
 func (d dijkstra) Len() int { return len(d.pq) }
@@ -0,0 +1,231 @@
+// Package dimacs reads graphs in the formats of the 9th DIMACS Implementation Challenge on shortest paths:
+// .gr files with the arcs of the graph and .co files with the coordinates of its nodes.
+//
+//	c <comment>
+//	p sp <n> <m>
+//	a <u> <v> <cost>
+//
+//	c <comment>
+//	p aux sp co <n>
+//	v <id> <x> <y>
+//
+// Nodes are numbered from 1 to n. Gzipped files are detected and decompressed transparently.
+package dimacs
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jcasado94/kstar"
+	"github.com/jcasado94/kstar/geo"
+	"github.com/jcasado94/kstar/graphio"
+)
+
+// Point holds the coordinates of a node.
+type Point = geo.Point
+
+// Coordinates holds the coordinates of the nodes of a graph, indexed by node id.
+type Coordinates = geo.Coordinates
+
+// ReadGraphFile reads a .gr file, gzipped or not.
+func ReadGraphFile(path string) (*kstar.AdjacencyGraph, error) {
+	var g *kstar.AdjacencyGraph
+	err := readFile(path, func(r io.Reader) (err error) {
+		g, err = ReadGraph(r)
+		return
+	})
+	return g, err
+}
+
+// ReadGraph reads a graph in the .gr format, gzipped or not. Malformed lines are reported as *graphio.ParseError.
+// Node ids are kept as in the input, node 0 being an isolated node. The graph departs from and arrives at node 1;
+// use Reroute to set the endpoints of a query.
+func ReadGraph(r io.Reader) (*kstar.AdjacencyGraph, error) {
+	var g *kstar.AdjacencyGraph
+	var n, m int
+	err := scan(r, func(vals []string) error {
+		switch {
+		case vals[0] == "p":
+			if g != nil {
+				return errors.New("duplicate p line")
+			}
+			if len(vals) != 4 || vals[1] != "sp" {
+				return errors.New("expected p sp <n> <m>")
+			}
+			ints, err := parseInts(vals[2:])
+			if err != nil {
+				return err
+			}
+			n, m = ints[0], ints[1]
+			if n <= 0 || m < 0 {
+				return fmt.Errorf("invalid node count %d or edge count %d", n, m)
+			}
+			g = kstar.NewAdjacencyGraph(n+1, 1, 1)
+		case vals[0] == "a" && g == nil:
+			return errors.New("arc before the p line")
+		case vals[0] == "a":
+			if len(vals) != 4 {
+				return errors.New("expected a <u> <v> <cost>")
+			}
+			ints, err := parseInts(vals[1:3])
+			if err != nil {
+				return err
+			}
+			cost, err := strconv.ParseFloat(vals[3], 64)
+			if err != nil {
+				return err
+			}
+			u, v := ints[0], ints[1]
+			if u < 1 || u > n || v < 1 || v > n {
+				return fmt.Errorf("arc %d %d out of range [1, %d]", u, v, n)
+			}
+			if cost <= 0 {
+				return fmt.Errorf("arc cost %g is not strictly positive", cost)
+			}
+			if g.NumEdges() == m {
+				return fmt.Errorf("more than the %d declared arcs", m)
+			}
+			g.AddEdge(u, v, cost)
+		default:
+			return fmt.Errorf("unexpected %q line", vals[0])
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if g == nil {
+		return nil, errors.New("missing p line")
+	}
+	if g.NumEdges() != m {
+		return nil, fmt.Errorf("found %d arcs, but %d were declared", g.NumEdges(), m)
+	}
+	return g, nil
+}
+
+// ReadCoordinatesFile reads a .co file, gzipped or not.
+func ReadCoordinatesFile(path string) (Coordinates, error) {
+	var coords Coordinates
+	err := readFile(path, func(r io.Reader) (err error) {
+		coords, err = ReadCoordinates(r)
+		return
+	})
+	return coords, err
+}
+
+// ReadCoordinates reads node coordinates in the .co format, gzipped or not.
+// Malformed lines are reported as *graphio.ParseError.
+func ReadCoordinates(r io.Reader) (Coordinates, error) {
+	var coords Coordinates
+	var n int
+	err := scan(r, func(vals []string) error {
+		switch {
+		case vals[0] == "p":
+			if coords != nil {
+				return errors.New("duplicate p line")
+			}
+			if len(vals) != 5 || vals[1] != "aux" || vals[2] != "sp" || vals[3] != "co" {
+				return errors.New("expected p aux sp co <n>")
+			}
+			ints, err := parseInts(vals[4:])
+			if err != nil {
+				return err
+			}
+			n = ints[0]
+			coords = make(Coordinates, n)
+		case vals[0] == "v" && coords == nil:
+			return errors.New("coordinates before the p line")
+		case vals[0] == "v":
+			if len(vals) != 4 {
+				return errors.New("expected v <id> <x> <y>")
+			}
+			ints, err := parseInts(vals[1:2])
+			if err != nil {
+				return err
+			}
+			x, err := strconv.ParseFloat(vals[2], 64)
+			if err != nil {
+				return err
+			}
+			y, err := strconv.ParseFloat(vals[3], 64)
+			if err != nil {
+				return err
+			}
+			id := ints[0]
+			if id < 1 || id > n {
+				return fmt.Errorf("node %d out of range [1, %d]", id, n)
+			}
+			if _, ok := coords[id]; ok {
+				return fmt.Errorf("duplicate coordinates for node %d", id)
+			}
+			coords[id] = Point{X: x, Y: y}
+		default:
+			return fmt.Errorf("unexpected %q line", vals[0])
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if coords == nil {
+		return nil, errors.New("missing p line")
+	}
+	return coords, nil
+}
+
+func readFile(path string, read func(r io.Reader) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := read(file); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// scan calls parse with the fields of every non comment line of r, decompressing it if gzipped.
+func scan(r io.Reader, parse func(vals []string) error) error {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		r = zr
+	} else {
+		r = br
+	}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		vals := strings.Fields(scanner.Text())
+		if len(vals) == 0 || vals[0] == "c" {
+			continue
+		}
+		if err := parse(vals); err != nil {
+			return &graphio.ParseError{Line: line, Err: err}
+		}
+	}
+	return scanner.Err()
+}
+
+func parseInts(vals []string) ([]int, error) {
+	ints := make([]int, len(vals))
+	for i, val := range vals {
+		num, err := strconv.Atoi(val)
+		if err != nil {
+			return nil, err
+		}
+		ints[i] = num
+	}
+	return ints, nil
+}
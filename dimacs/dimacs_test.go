@@ -0,0 +1,115 @@
+package dimacs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jcasado94/kstar"
+	"github.com/jcasado94/kstar/graphio"
+)
+
+const (
+	testGraph = `c 2x2 grid with a diagonal
+p sp 4 6
+a 1 2 10
+a 2 4 10
+a 1 3 12
+a 3 4 11
+a 1 4 15
+a 4 1 15
+`
+	testCoordinates = `p aux sp co 4
+v 1 0 0
+v 2 10 0
+v 3 0 10
+v 4 10 10
+`
+)
+
+func TestReadGraph(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(testGraph))
+	zw.Close()
+
+	for _, input := range []string{testGraph, buf.String()} {
+		g, err := ReadGraph(strings.NewReader(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if g.NumNodes() != 5 || g.NumEdges() != 6 {
+			t.Errorf("Expected 5 nodes and 6 edges, but found %d nodes and %d edges.", g.NumNodes(), g.NumEdges())
+		}
+		if cost := g.EdgeCost(3, 4, 0); cost != 11 {
+			t.Errorf("Expected arc 3 4 to cost 11, but found %f.", cost)
+		}
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	inputs := []string{
+		"a 1 2 3\n",
+		"p sp 2 1\na 1 3 1\n",
+		"p sp 2 1\na 1 2\n",
+		"p sp 2 1\na 1 2 0\n",
+		"p aux sp co 2\nv 1 0 0\nv 1 0 0\n",
+	}
+	for _, input := range inputs {
+		_, errGraph := ReadGraph(strings.NewReader(input))
+		_, errCoords := ReadCoordinates(strings.NewReader(input))
+		var pe *graphio.ParseError
+		if !errors.As(errGraph, &pe) && !errors.As(errCoords, &pe) {
+			t.Errorf("Expected a parse error reading %q, but found %v and %v.", input, errGraph, errCoords)
+		}
+	}
+}
+
+func TestHeuristic(t *testing.T) {
+	g, err := ReadGraph(strings.NewReader(testGraph))
+	if err != nil {
+		t.Fatal(err)
+	}
+	coords, err := ReadCoordinates(strings.NewReader(testCoordinates))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := NewHeuristic(g, coords, Euclidean)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Scale() != 1 {
+		t.Errorf("Expected scale 1, but found %f.", h.Scale())
+	}
+
+	query := g.Reroute(1, 4)
+	expectedPaths := kstar.Run(query, 4)
+	paths := kstar.Run(h.Graph(query), 4)
+	if len(paths) != len(expectedPaths) {
+		t.Fatalf("Expected %d paths, but found %d.", len(expectedPaths), len(paths))
+	}
+	for i := range paths {
+		if cost, expectedCost := pathCost(g, paths[i]), pathCost(g, expectedPaths[i]); cost != expectedCost {
+			t.Errorf("Path %d costs %f, but expected %f.", i, cost, expectedCost)
+		}
+	}
+
+	gc, err := NewHeuristic(g, coords, GreatCircle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for n := 1; n <= 4; n++ {
+		if e := gc.Estimate(n, 4); e > pathCost(g, kstar.Run(g.Reroute(n, 4), 1)[0]) {
+			t.Errorf("Great circle estimate %f from %d is not admissible.", e, n)
+		}
+	}
+}
+
+func pathCost(g *kstar.AdjacencyGraph, path []kstar.Edge) (cost float64) {
+	for _, e := range path {
+		cost += g.EdgeCost(e.U, e.V, e.I)
+	}
+	return
+}
@@ -0,0 +1,46 @@
+package dimacs
+
+import (
+	"fmt"
+
+	"github.com/jcasado94/kstar"
+	"github.com/jcasado94/kstar/geo"
+)
+
+// Metric is the distance between the coordinates of two nodes a Heuristic is based on.
+type Metric int
+
+const (
+	// Euclidean is the straight line distance between the coordinates.
+	Euclidean Metric = iota
+	// GreatCircle is the distance in meters over the Earth's surface, X and Y being the longitude and the latitude
+	// in millionths of a degree, as in the USA road networks of the challenge.
+	GreatCircle
+)
+
+// Heuristic estimates the cost between two nodes as the distance between their coordinates, multiplied by the
+// largest factor keeping the estimate of every arc below its cost. The estimate is thus admissible and consistent
+// for any target.
+type Heuristic struct {
+	*geo.Heuristic
+}
+
+// NewHeuristic derives a Heuristic from the arcs of g and the coordinates of its nodes.
+// Every node with arcs must have coordinates.
+func NewHeuristic(g *kstar.AdjacencyGraph, coords Coordinates, metric Metric) (*Heuristic, error) {
+	for _, e := range g.Edges() {
+		for _, n := range []int{e.U, e.V} {
+			if _, ok := coords[n]; !ok {
+				return nil, fmt.Errorf("missing coordinates for node %d", n)
+			}
+		}
+	}
+	points, m := coords, geo.Euclidean
+	if metric == GreatCircle {
+		points, m = make(Coordinates, len(coords)), geo.Haversine
+		for n, p := range coords {
+			points[n] = Point{X: p.X / 1e6, Y: p.Y / 1e6}
+		}
+	}
+	return &Heuristic{geo.NewHeuristic(points, m, geo.MaxScale(g, points, m))}, nil
+}
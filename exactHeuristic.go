@@ -0,0 +1,25 @@
+package kstar
+
+import "math"
+
+// ExactHeuristic returns a Graph behaving as g whose FValue is the exact cost to g.T(), computed once by a Dijkstra
+// search from T() along the incoming edges of g. Nodes that cannot reach T() have an FValue of +Inf, so A* expands
+// them last. A* then expands nodes in the order of the cheapest path through them, reaching T() along a shortest path
+// without expanding any other node, barring ties.
+//
+// The costs of every node reaching T() are held in memory. g must not be modified while the returned Graph is used.
+func ExactHeuristic(g ReverseGraph) Graph {
+	return WrapGraph(exactGraph{ReverseGraph: g, cost: costsTo(g.T(), g.Incoming)}, g)
+}
+
+type exactGraph struct {
+	ReverseGraph
+	cost map[int]float64
+}
+
+func (g exactGraph) FValue(n int) float64 {
+	if c, ok := g.cost[n]; ok {
+		return c
+	}
+	return math.Inf(1)
+}
@@ -0,0 +1,70 @@
+package kstar
+
+import (
+	"math"
+	"testing"
+)
+
+func TestIncoming(t *testing.T) {
+	g := newGridGraph(5, 4)
+	g.AddEdge(3, 3, 1)
+	g.AddEdge(3, 3, 2)
+	for u := 0; u < g.NumNodes(); u++ {
+		for v, costs := range g.Connections(u) {
+			in := g.Incoming(v)[u]
+			if len(in) != len(costs) {
+				t.Fatalf("Expected %d edges from %d in Incoming(%d), but found %d.", len(costs), u, v, len(in))
+			}
+			for i := range costs {
+				if in[i] != costs[i] {
+					t.Errorf("Edge %d->%d #%d costs %f, but Incoming says %f.", u, v, i, costs[i], in[i])
+				}
+			}
+		}
+	}
+	if g.Incoming(-1) != nil || g.Incoming(g.NumNodes()) != nil {
+		t.Error("Expected no incoming edges for nodes out of the graph.")
+	}
+}
+
+func TestExactHeuristic(t *testing.T) {
+	g := newGridGraph(12, 12)
+	// a dead end
+	dead := g.NumNodes()
+	g.AddEdge(0, dead, 1)
+
+	eg := ExactHeuristic(g)
+	if _, ok := eg.(EdgeCoster); !ok {
+		t.Error("Expected the exact heuristic graph of an EdgeCoster to be one.")
+	}
+	if h := eg.FValue(g.T()); h != 0 {
+		t.Errorf("Expected an FValue of 0 at T, but found %f.", h)
+	}
+	if h := eg.FValue(dead); !math.IsInf(h, 1) {
+		t.Errorf("Expected an infinite FValue for a dead end, but found %f.", h)
+	}
+	report := CheckHeuristic(eg)
+	if !report.Admissible() || !report.Consistent() {
+		t.Errorf("Expected an admissible and consistent heuristic, but found %+v.", report)
+	}
+
+	paths, stats := RunWithOptions(g, 20)
+	exactPaths, exactStats := RunWithOptions(eg, 20)
+	if len(paths) != len(exactPaths) {
+		t.Fatalf("Expected %d paths, but found %d.", len(paths), len(exactPaths))
+	}
+	for i := range paths {
+		if pathCost(g, paths[i]) != pathCost(g, exactPaths[i]) {
+			t.Errorf("Path %d costs %f, but expected %f.", i, pathCost(g, exactPaths[i]), pathCost(g, paths[i]))
+		}
+	}
+	if exactStats.ExpandedNodes >= stats.ExpandedNodes {
+		t.Errorf("Expected fewer than %d expansions, but found %d.", stats.ExpandedNodes, exactStats.ExpandedNodes)
+	}
+
+	// the shortest path is the only one expanded
+	paths, stats = RunWithOptions(eg, 1)
+	if stats.ExpandedNodes > len(paths[0]) {
+		t.Errorf("Expected at most %d expansions for the shortest path, but found %d.", len(paths[0]), stats.ExpandedNodes)
+	}
+}
@@ -0,0 +1,46 @@
+package kstar
+
+// Explanation describes a path found by K* as the shortest path to T() in the search tree of A* with detours. The
+// path follows the tree except for its sidetracks, each adding its detour to the cost of the shortest path, so that
+// it reads as "same as the shortest path, except take Edge instead of Instead (+Detour)".
+type Explanation struct {
+	// BaseCost is the cost of the shortest path in the tree. The path costs BaseCost plus the detours.
+	BaseCost float64
+	// Sidetracks are the edges of the path off the tree, in order from S() to T().
+	Sidetracks []Sidetrack
+	// Segments are the tree edges of the path around its sidetracks, in order from S() to T(): Segments[0] leads from
+	// S() to the first sidetrack and Segments[i] from the ith sidetrack to the next one or to T(). Segments may be
+	// empty.
+	Segments [][]Edge
+}
+
+// Sidetrack is an edge of a path off the search tree.
+type Sidetrack struct {
+	Edge Edge
+	// Instead is the tree edge arriving at Edge.V, the last one of the shortest path to Edge.V. It is the zero Edge
+	// if Edge.V is S().
+	Instead Edge
+	// Detour is how much more the path costs by taking Edge instead of the shortest path to Edge.V.
+	Detour float64
+}
+
+// explain describes path, given from T() to S() as Run returns it, whose sidetracks are seq, from S() to T().
+func (as *astar) explain(seq, path []Edge) Explanation {
+	ex := Explanation{
+		BaseCost:   as.minPathCost(),
+		Sidetracks: make([]Sidetrack, 0, len(seq)),
+		Segments:   [][]Edge{{}},
+	}
+	for i := len(path) - 1; i >= 0; i-- {
+		e := path[i]
+		if len(ex.Sidetracks) < len(seq) && e == seq[len(ex.Sidetracks)] {
+			// sidetracks are never tree edges, they come in the order of the path
+			ex.Sidetracks = append(ex.Sidetracks, Sidetrack{Edge: e, Instead: as.searchTreeParents[e.V], Detour: as.dValue(e)})
+			ex.Segments = append(ex.Segments, []Edge{})
+			continue
+		}
+		last := len(ex.Segments) - 1
+		ex.Segments[last] = append(ex.Segments[last], e)
+	}
+	return ex
+}
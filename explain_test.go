@@ -0,0 +1,77 @@
+package kstar
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestExplanations(t *testing.T) {
+	rnd := rand.New(rand.NewSource(5))
+	for test := 0; test < 200; test++ {
+		n := 2 + rnd.Intn(6)
+		g := randomGraph(rnd, n, rnd.Intn(3*n))
+
+		paths, stats := RunWithOptions(g, 8, WithExplanations())
+		if len(stats.Explanations) != len(paths) {
+			t.Fatalf("Graph %d %v failed! Expected %d explanations, but found %d.", test, g, len(paths), len(stats.Explanations))
+		}
+		for i, path := range paths {
+			ex := stats.Explanations[i]
+			cost := ex.BaseCost
+			var rebuilt []Edge
+			for j, st := range ex.Sidetracks {
+				cost += st.Detour
+				rebuilt = append(rebuilt, ex.Segments[j]...)
+				rebuilt = append(rebuilt, st.Edge)
+				if st.Edge.V != g.S() && st.Instead.V != st.Edge.V {
+					t.Errorf("Graph %d %v failed! Sidetrack %v of path %d replaces %v.", test, g, st.Edge, i, st.Instead)
+				}
+			}
+			rebuilt = append(rebuilt, ex.Segments[len(ex.Segments)-1]...)
+			if len(ex.Segments) != len(ex.Sidetracks)+1 {
+				t.Errorf("Graph %d %v failed! Path %d has %d sidetracks and %d segments.", test, g, i, len(ex.Sidetracks), len(ex.Segments))
+			}
+			if math.Abs(cost-pathCost(g, path)) > 1e-9 {
+				t.Errorf("Graph %d %v failed! Path %d costs %f, but its explanation adds up to %f.", test, g, i, pathCost(g, path), cost)
+			}
+			reversed := make([]Edge, len(path))
+			for j, e := range path {
+				reversed[len(path)-1-j] = e
+			}
+			if len(reversed) > 0 && !reflect.DeepEqual(rebuilt, reversed) {
+				t.Errorf("Graph %d %v failed! Path %d is %v, but its explanation rebuilds %v.", test, g, i, reversed, rebuilt)
+			}
+		}
+		if len(paths) > 0 && len(stats.Explanations[0].Sidetracks) != 0 {
+			t.Errorf("Graph %d %v failed! The shortest path has sidetracks %v.", test, g, stats.Explanations[0].Sidetracks)
+		}
+	}
+
+	if _, stats := RunWithOptions(newDiamondGraph(), 4); stats.Explanations != nil {
+		t.Errorf("Expected no explanations by default, but found %v.", stats.Explanations)
+	}
+}
+
+func TestResultSidetracks(t *testing.T) {
+	g := newDiamondGraph()
+	paths, stats := RunWithOptions(g, 4, WithExplanations())
+	res := NewResult("", g, 4, paths, &stats)
+	for i, rp := range res.Paths {
+		ex := stats.Explanations[i]
+		if len(rp.Sidetracks) != len(ex.Sidetracks) {
+			t.Fatalf("Path %d has %d sidetracks in the result, but %d in its explanation.", i, len(rp.Sidetracks), len(ex.Sidetracks))
+		}
+		for j, rs := range rp.Sidetracks {
+			st := ex.Sidetracks[j]
+			if rs.Edge != (ResultEdge{U: st.Edge.U, V: st.Edge.V, I: st.Edge.I}) || rs.Detour != st.Detour || rs.Instead == nil ||
+				*rs.Instead != (ResultEdge{U: st.Instead.U, V: st.Instead.V, I: st.Instead.I}) {
+				t.Errorf("Sidetrack %d of path %d is %+v, but expected %+v.", j, i, rs, st)
+			}
+			if rp.Delta != rs.Detour && len(rp.Sidetracks) == 1 {
+				t.Errorf("Path %d is %f longer than the first one, but its only detour is %f.", i, rp.Delta, rs.Detour)
+			}
+		}
+	}
+}
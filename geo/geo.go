@@ -0,0 +1,178 @@
+// Package geo estimates costs on spatial graphs from the coordinates of their nodes: the estimate between two nodes is
+// their distance in some metric times a cost per unit of distance. The estimate is admissible for any target as long
+// as no edge costs less than the estimate between its ends, which Check verifies and MaxScale ensures.
+package geo
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/jcasado94/kstar"
+)
+
+// Point holds the coordinates of a node.
+type Point struct {
+	X, Y float64
+}
+
+// Coordinates holds the coordinates of the nodes of a graph, indexed by node id.
+type Coordinates map[int]Point
+
+// Metric is the distance between two points a Heuristic is based on.
+type Metric int
+
+const (
+	// Euclidean is the straight line distance.
+	Euclidean Metric = iota
+	// Manhattan is the sum of the distances along each axis, for moves along the axes only.
+	Manhattan
+	// Octile is the distance moving along the axes and diagonally, each diagonal move covering one unit along both
+	// axes at the cost of the square root of 2.
+	Octile
+	// Haversine is the great circle distance in meters over the Earth's surface, X and Y being the longitude and the
+	// latitude in degrees.
+	Haversine
+)
+
+const (
+	earthRadius = 6371008.8 // mean radius in meters
+	tolerance   = 1e-9      // relative excess of an estimate over a cost attributed to rounding
+)
+
+// Distance returns the distance between p and q.
+func (m Metric) Distance(p, q Point) float64 {
+	dx, dy := math.Abs(p.X-q.X), math.Abs(p.Y-q.Y)
+	switch m {
+	case Manhattan:
+		return dx + dy
+	case Octile:
+		return dx + dy + (math.Sqrt2-2)*math.Min(dx, dy)
+	case Haversine:
+		const toRadians = math.Pi / 180
+		lat1, lat2 := p.Y*toRadians, q.Y*toRadians
+		dLat, dLon := lat2-lat1, (q.X-p.X)*toRadians
+		a := math.Pow(math.Sin(dLat/2), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin(dLon/2), 2)
+		return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+	}
+	return math.Hypot(dx, dy)
+}
+
+// Heuristic estimates the cost between two nodes as the distance between their coordinates times a scale, the
+// cheapest cost per unit of distance, the inverse of the highest speed for travel times.
+type Heuristic struct {
+	coords Coordinates
+	metric Metric
+	scale  float64
+}
+
+// NewHeuristic returns the Heuristic of the nodes in coords with the given metric and scale.
+func NewHeuristic(coords Coordinates, metric Metric, scale float64) *Heuristic {
+	return &Heuristic{coords: coords, metric: metric, scale: scale}
+}
+
+// Scale returns the factor the distances are multiplied by.
+func (h *Heuristic) Scale() float64 {
+	return h.scale
+}
+
+// Estimate returns the estimated cost from u to v, 0 if any of them has no coordinates.
+func (h *Heuristic) Estimate(u, v int) float64 {
+	p, ok := h.coords[u]
+	if !ok {
+		return 0
+	}
+	q, ok := h.coords[v]
+	if !ok {
+		return 0
+	}
+	return h.scale * h.metric.Distance(p, q)
+}
+
+// Graph returns a Graph behaving as g whose FValue is the estimate to g.T().
+func (h *Heuristic) Graph(g kstar.Graph) kstar.Graph {
+	return kstar.WrapGraph(heuristicGraph{Graph: g, h: h}, g)
+}
+
+type heuristicGraph struct {
+	kstar.Graph
+	h *Heuristic
+}
+
+func (g heuristicGraph) FValue(n int) float64 {
+	return g.h.Estimate(n, g.T())
+}
+
+// ScaleError reports an edge cheaper than the estimate between its ends.
+type ScaleError struct {
+	Edge     kstar.Edge
+	Cost     float64
+	Estimate float64
+	// MaxScale is the largest scale keeping the estimate of every edge below its cost.
+	MaxScale float64
+}
+
+func (e *ScaleError) Error() string {
+	return fmt.Sprintf("edge %d->%d costs %g, less than its estimate %g; the scale must be at most %g",
+		e.Edge.U, e.Edge.V, e.Cost, e.Estimate, e.MaxScale)
+}
+
+// Check verifies that the estimate of every edge from a node with coordinates is at most its cost in g.Connections,
+// which makes the heuristic admissible and consistent, returning a *ScaleError for the edge exceeding its cost the
+// most otherwise.
+func (h *Heuristic) Check(g kstar.Graph) error {
+	var worst *ScaleError
+	h.edges(g, func(e kstar.Edge, cost, dist float64) {
+		if estimate := h.scale * dist; estimate > cost*(1+tolerance) && (worst == nil || estimate-cost > worst.Estimate-worst.Cost) {
+			worst = &ScaleError{Edge: e, Cost: cost, Estimate: estimate}
+		}
+	})
+	if worst == nil {
+		return nil
+	}
+	worst.MaxScale = MaxScale(g, h.coords, h.metric)
+	return worst
+}
+
+// MaxScale returns the largest scale keeping the estimate of every edge from a node in coords at most its cost in
+// g.Connections, 0 if no edge joins two distinct points.
+func MaxScale(g kstar.Graph, coords Coordinates, metric Metric) float64 {
+	h := Heuristic{coords: coords, metric: metric}
+	scale := math.Inf(1)
+	h.edges(g, func(e kstar.Edge, cost, dist float64) {
+		if dist > 0 {
+			scale = math.Min(scale, cost/dist)
+		}
+	})
+	if math.IsInf(scale, 1) {
+		return 0
+	}
+	return scale
+}
+
+// edges calls f with every edge between two nodes with coordinates, its cost and the distance between its ends.
+func (h *Heuristic) edges(g kstar.Graph, f func(e kstar.Edge, cost, dist float64)) {
+	nodes := make([]int, 0, len(h.coords))
+	for n := range h.coords {
+		nodes = append(nodes, n)
+	}
+	sort.Ints(nodes)
+	for _, u := range nodes {
+		conns := g.Connections(u)
+		vs := make([]int, 0, len(conns))
+		for v := range conns {
+			vs = append(vs, v)
+		}
+		sort.Ints(vs)
+		for _, v := range vs {
+			q, ok := h.coords[v]
+			if !ok {
+				continue
+			}
+			dist := h.metric.Distance(h.coords[u], q)
+			for i, cost := range conns[v] {
+				f(kstar.Edge{U: u, V: v, I: i}, cost, dist)
+			}
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package geo
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/jcasado94/kstar"
+)
+
+func TestDistance(t *testing.T) {
+	p, q := Point{0, 0}, Point{3, 4}
+	tests := []struct {
+		metric   Metric
+		p, q     Point
+		expected float64
+	}{
+		{Euclidean, p, q, 5},
+		{Manhattan, p, q, 7},
+		{Octile, p, q, 1 + 3*math.Sqrt2},
+		{Haversine, Point{2, 41}, Point{2, 42}, earthRadius * math.Pi / 180},
+	}
+	for _, test := range tests {
+		if d := test.metric.Distance(test.p, test.q); math.Abs(d-test.expected) > 1e-6 {
+			t.Errorf("Metric %d: expected %f, but found %f.", test.metric, test.expected, d)
+		}
+		if d := test.metric.Distance(test.q, test.p); math.Abs(d-test.expected) > 1e-6 {
+			t.Errorf("Metric %d is not symmetric: expected %f, but found %f.", test.metric, test.expected, d)
+		}
+	}
+}
+
+// newGrid returns a w by h 8-connected grid whose edges take the octile distance between their ends at a speed of
+// 2, so they cost half of it, and the coordinates of its nodes.
+func newGrid(w, h int) (*kstar.AdjacencyGraph, Coordinates) {
+	g := kstar.NewAdjacencyGraph(w*h, 0, w*h-1)
+	coords := make(Coordinates, w*h)
+	for n := 0; n < w*h; n++ {
+		coords[n] = Point{X: float64(n % w), Y: float64(n / w)}
+	}
+	for u := 0; u < w*h; u++ {
+		for v := 0; v < w*h; v++ {
+			if d := Octile.Distance(coords[u], coords[v]); u != v && d < 1.5 {
+				g.AddEdge(u, v, d/2)
+			}
+		}
+	}
+	return g, coords
+}
+
+func pathCosts(g *kstar.AdjacencyGraph, paths [][]kstar.Edge) (costs []float64) {
+	for _, path := range paths {
+		cost := 0.0
+		for _, e := range path {
+			cost += g.EdgeCost(e.U, e.V, e.I)
+		}
+		costs = append(costs, math.Round(cost*1e9)/1e9)
+	}
+	return costs
+}
+
+func TestHeuristic(t *testing.T) {
+	g, coords := newGrid(12, 12)
+	if scale := MaxScale(g, coords, Octile); math.Abs(scale-0.5) > 1e-9 {
+		t.Errorf("Expected a maximum scale of 0.5, but found %f.", scale)
+	}
+
+	h := NewHeuristic(coords, Octile, 0.5)
+	if err := h.Check(g); err != nil {
+		t.Error(err)
+	}
+	if _, ok := h.Graph(g).(kstar.EdgeCoster); !ok {
+		t.Error("Expected the heuristic graph of an EdgeCoster to be one.")
+	}
+	if _, ok := h.Graph(g).(kstar.ReverseGraph); !ok {
+		t.Error("Expected the heuristic graph of a ReverseGraph to be one.")
+	}
+	paths, stats := kstar.RunWithOptions(g, 10)
+	hPaths, hStats := kstar.RunWithOptions(h.Graph(g), 10)
+	if !reflect.DeepEqual(pathCosts(g, paths), pathCosts(g, hPaths)) {
+		t.Errorf("Expected the costs %v, but found %v.", pathCosts(g, paths), pathCosts(g, hPaths))
+	}
+	if hStats.ExpandedNodes >= stats.ExpandedNodes {
+		t.Errorf("Expected fewer than %d expansions, but found %d.", stats.ExpandedNodes, hStats.ExpandedNodes)
+	}
+	if e := h.Estimate(0, 1000); e != 0 {
+		t.Errorf("Expected no estimate to a node without coordinates, but found %f.", e)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	g, coords := newGrid(4, 4)
+	// Euclidean distances are at most the octile ones, Manhattan ones overestimate diagonal edges
+	if err := NewHeuristic(coords, Euclidean, 0.5).Check(g); err != nil {
+		t.Error(err)
+	}
+	err := NewHeuristic(coords, Manhattan, 0.5).Check(g)
+	var se *ScaleError
+	if !errors.As(err, &se) {
+		t.Fatalf("Expected a scale error, but found %v.", err)
+	}
+	if se.Edge.U == se.Edge.V-1 || se.Estimate <= se.Cost || math.Abs(se.MaxScale-math.Sqrt2/4) > 1e-9 {
+		t.Errorf("Unexpected error %+v.", se)
+	}
+	if err := NewHeuristic(coords, Manhattan, se.MaxScale).Check(g); err != nil {
+		t.Errorf("Expected the maximum scale to be admissible, but found %v.", err)
+	}
+}
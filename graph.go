@@ -1,11 +1,19 @@
+// Package kstar finds the k shortest paths between two nodes of a graph with the K* algorithm.
+//
+// A query only reads from its Graph. It never modifies the maps and slices returned by Connections, so a Graph may
+// return its own data, and it keeps no reference to them once it returns. Each query has its own state, so any
+// number of queries may run at the same time on one Graph as long as its methods are safe for concurrent use, which
+// holds for a Graph that is not modified while queried, such as AdjacencyGraph, and for CachedGraph. An Observer or a
+// ResumptionPolicy given to several concurrent queries is called from all of them.
 package kstar
 
-// Graph defines the graph interface used by K*.
+// Graph defines the graph interface used by K*. See the package documentation for its use by concurrent queries.
 type Graph interface {
 
 	// Connections is the implicit representation of our graph.
 	// Given a graph node represented by non-negative integer n, it returns costs of the edges from n to any other node.
 	// Edge costs must be strictly positive. Loops allowed. Keep complexity on O(1).
+	// The returned map is only read by K*.
 	Connections(n int) map[int][]float64
 
 	// S returns the departure node.
@@ -18,26 +26,119 @@ type Graph interface {
 	FValue(n int) float64
 }
 
+// EdgeCoster is an optional interface a Graph can implement to provide the cost of a single edge
+// without building the whole map returned by Connections.
+type EdgeCoster interface {
+
+	// EdgeCost returns the cost of the ith edge from u to v, as defined in Graph.Connections(u)[v][i].
+	EdgeCost(u, v, i int) float64
+}
+
+// ReverseGraph is an optional interface a Graph can implement to list the edges arriving at a node, which allows
+// searching it backwards from T(), see ExactHeuristic.
+type ReverseGraph interface {
+	Graph
+
+	// Incoming returns the costs of the edges from any other node to n. Incoming(v)[u][i] is the cost of the ith edge
+	// from u to v, Connections(u)[v][i]. The returned map is only read.
+	Incoming(n int) map[int][]float64
+}
+
+// WrapGraph returns wrapper, a Graph built on g that overrides some of its methods but not its edges, such as a
+// heuristic embedding g, extended with the optional interfaces g implements and wrapper does not, forwarded to g.
+// Embedding g as a Graph hides them otherwise: K* falls back to reading costs from Connections, and dead ends cannot
+// be pruned.
+func WrapGraph(wrapper, g Graph) Graph {
+	coster, hasCoster := wrapper.(EdgeCoster)
+	if !hasCoster {
+		coster, _ = g.(EdgeCoster)
+	}
+	reverse, hasReverse := asReverseGraph(wrapper)
+	if !hasReverse {
+		reverse, _ = asReverseGraph(g)
+	}
+	switch {
+	case (hasCoster || coster == nil) && (hasReverse || reverse == nil):
+		return wrapper
+	case reverse == nil:
+		return costerWrapper{Graph: wrapper, coster: coster}
+	case coster == nil:
+		return reverseWrapper{Graph: wrapper, reverse: reverse}
+	}
+	return reverseCosterWrapper{costerWrapper: costerWrapper{Graph: wrapper, coster: coster}, reverse: reverse}
+}
+
+// asReverseGraph returns g as a ReverseGraph if it is one. A CachedGraph always has an Incoming method, but is only a
+// ReverseGraph if the Graph it wraps is.
+func asReverseGraph(g Graph) (ReverseGraph, bool) {
+	if cg, ok := g.(*CachedGraph); ok && cg.reverse == nil {
+		return nil, false
+	}
+	rg, ok := g.(ReverseGraph)
+	return rg, ok
+}
+
+// costerWrapper forwards EdgeCost to the wrapped Graph.
+type costerWrapper struct {
+	Graph
+	coster EdgeCoster
+}
+
+func (w costerWrapper) EdgeCost(u, v, i int) float64 {
+	return w.coster.EdgeCost(u, v, i)
+}
+
+// reverseWrapper forwards Incoming to the wrapped Graph.
+type reverseWrapper struct {
+	Graph
+	reverse ReverseGraph
+}
+
+func (w reverseWrapper) Incoming(n int) map[int][]float64 {
+	return w.reverse.Incoming(n)
+}
+
+// reverseCosterWrapper forwards both EdgeCost and Incoming to the wrapped Graph.
+type reverseCosterWrapper struct {
+	costerWrapper
+	reverse ReverseGraph
+}
+
+func (w reverseCosterWrapper) Incoming(n int) map[int][]float64 {
+	return w.reverse.Incoming(n)
+}
+
 // Edge represents an Edge defined in Graph.Connections(), specifically the ith from u to v.
 type Edge struct {
 	U, V, I int
 }
 
-// RemoveLoopPaths removes the paths which contain loops.
+// RemoveLoopPaths removes the paths which contain loops. Paths can be given from S to T or, as Run returns them,
+// from T to S.
 func RemoveLoopPaths(paths [][]Edge) (modifiedPaths [][]Edge) {
 	for _, path := range paths {
-		admittable := true
-		beenTo := map[int]bool{path[0].U: true}
-		for _, edge := range path {
-			if beenTo[edge.V] {
-				admittable = false
-				break
-			}
-			beenTo[edge.V] = true
-		}
-		if admittable {
+		if !hasLoops(path) {
 			modifiedPaths = append(modifiedPaths, path)
 		}
 	}
 	return modifiedPaths
 }
+
+func hasLoops(path []Edge) bool {
+	if len(path) == 0 {
+		return false
+	}
+	first := path[0].U
+	if len(path) > 1 && path[0].U == path[1].V {
+		// from T to S
+		first = path[len(path)-1].U
+	}
+	beenTo := map[int]bool{first: true}
+	for _, edge := range path {
+		if beenTo[edge.V] {
+			return true
+		}
+		beenTo[edge.V] = true
+	}
+	return false
+}
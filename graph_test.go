@@ -24,3 +24,46 @@ func TestRemoveLoopPaths(t *testing.T) {
 		t.Errorf("RemoveLoopPaths failed.\nExpected\n%v\ngot\n%v", expectedPaths, modifiedPaths)
 	}
 }
+
+func TestRemoveLoopPathsFromT(t *testing.T) {
+	pathWithoutLoops := []Edge{{U: 1, V: 2}, {U: 0, V: 1}}
+	pathWithLoops := []Edge{{U: 1, V: 2}, {U: 0, V: 1}, {U: 1, V: 0}, {U: 0, V: 1}}
+	modifiedPaths := RemoveLoopPaths([][]Edge{pathWithoutLoops, pathWithLoops, {}})
+	if len(modifiedPaths) != 2 || len(modifiedPaths[0]) != 2 || len(modifiedPaths[1]) != 0 {
+		t.Errorf("RemoveLoopPaths failed on paths from T to S, got\n%v", modifiedPaths)
+	}
+}
+
+type fValueWrapper struct {
+	Graph
+}
+
+func (fValueWrapper) FValue(n int) float64 { return 0 }
+
+func TestWrapGraph(t *testing.T) {
+	g := NewAdjacencyGraph(2, 0, 1)
+	g.AddEdge(0, 1, 3)
+	coster, ok := WrapGraph(fValueWrapper{g}, g).(EdgeCoster)
+	if !ok {
+		t.Fatal("Expected a wrapped EdgeCoster to be one.")
+	}
+	if cost := coster.EdgeCost(0, 1, 0); cost != 3 {
+		t.Errorf("Expected a cost of 3, but found %f.", cost)
+	}
+	reverse, ok := WrapGraph(fValueWrapper{g}, g).(ReverseGraph)
+	if !ok {
+		t.Fatal("Expected a wrapped ReverseGraph to be one.")
+	}
+	if in := reverse.Incoming(1); len(in[0]) != 1 || in[0][0] != 3 {
+		t.Errorf("Expected the edge from 0 to arrive at 1, but found %v.", in)
+	}
+	if _, ok := WrapGraph(fValueWrapper{g}, fValueWrapper{g}).(ReverseGraph); ok {
+		t.Error("Expected a Graph wrapping a plain Graph not to be a ReverseGraph.")
+	}
+	if _, ok := WrapGraph(fValueWrapper{newDiamondGraph()}, newDiamondGraph()).(EdgeCoster); ok {
+		t.Error("Expected a wrapped Graph not to be an EdgeCoster.")
+	}
+	if _, ok := WrapGraph(g, g).(*AdjacencyGraph); !ok {
+		t.Error("Expected a wrapper implementing every optional interface to be returned as is.")
+	}
+}
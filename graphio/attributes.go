@@ -0,0 +1,268 @@
+package graphio
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jcasado94/kstar"
+)
+
+// Attributes names the attributes holding the data of a K* graph in the GraphML and DOT formats. Empty names take
+// the defaults.
+type Attributes struct {
+	// Cost is the edge attribute holding the cost of an edge, "cost" by default. Every edge must have it.
+	Cost string
+	// Index is the edge attribute ordering parallel edges, "index" by default. Parallel edges are numbered by
+	// ascending index, edges without one following in the order they appear.
+	Index string
+	// Heuristic is the node attribute holding the heuristic value of a node, "h" by default.
+	Heuristic string
+	// S and T are the graph attributes naming the departure and arrival nodes, "s" and "t" by default.
+	// Both are node 0 if not set.
+	S, T string
+}
+
+func (a Attributes) withDefaults() Attributes {
+	set := func(name *string, def string) {
+		if *name == "" {
+			*name = def
+		}
+	}
+	set(&a.Cost, "cost")
+	set(&a.Index, "index")
+	set(&a.Heuristic, "h")
+	set(&a.S, "s")
+	set(&a.T, "t")
+	return a
+}
+
+// builder assembles a graph from the named nodes and the edges of a GraphML or DOT file.
+type builder struct {
+	attrs      Attributes
+	names      []string
+	nodes      map[string]int
+	nodeAttrs  []map[string]string
+	edges      []rawEdge
+	graphAttrs map[string]string
+}
+
+// rawEdge is an edge between two nodes of a builder, in the order they appeared. line is 0 if unknown.
+type rawEdge struct {
+	u, v  int
+	attrs map[string]string
+	line  int
+}
+
+func newBuilder(attrs Attributes) *builder {
+	return &builder{
+		attrs:      attrs.withDefaults(),
+		nodes:      make(map[string]int),
+		graphAttrs: make(map[string]string),
+	}
+}
+
+// node returns the position of the node called name, adding it with attrs if new.
+func (b *builder) node(name string, attrs map[string]string) int {
+	if n, ok := b.nodes[name]; ok {
+		return n
+	}
+	b.nodes[name] = len(b.names)
+	b.names = append(b.names, name)
+	b.nodeAttrs = append(b.nodeAttrs, copyAttrs(attrs))
+	return len(b.names) - 1
+}
+
+// setNodeAttrs sets attributes of the node at position n.
+func (b *builder) setNodeAttrs(n int, attrs map[string]string) {
+	for name, val := range attrs {
+		b.nodeAttrs[n][name] = val
+	}
+}
+
+// edge adds an edge between the nodes at positions u and v, and the one back if undirected.
+func (b *builder) edge(u, v int, attrs map[string]string, directed bool, line int) {
+	b.edges = append(b.edges, rawEdge{u: u, v: v, attrs: copyAttrs(attrs), line: line})
+	if !directed && u != v {
+		b.edges = append(b.edges, rawEdge{u: v, v: u, attrs: b.edges[len(b.edges)-1].attrs, line: line})
+	}
+}
+
+// build returns the graph and the name of each of its nodes. If every node name is an integer, optionally prefixed
+// by n as usual in GraphML, names are taken as node ids. Otherwise nodes are numbered in the order they appeared.
+func (b *builder) build() (*kstar.AdjacencyGraph, []string, error) {
+	ids := b.ids()
+	n := 0
+	for _, id := range ids {
+		if id >= n {
+			n = id + 1
+		}
+	}
+	names := make([]string, n)
+	for pos, id := range ids {
+		names[id] = b.names[pos]
+	}
+
+	endpoint := func(attr string) (int, error) {
+		name, ok := b.graphAttrs[attr]
+		if !ok {
+			return 0, nil
+		}
+		pos, ok := b.nodes[name]
+		if !ok {
+			return 0, fmt.Errorf("%s node %q not found", attr, name)
+		}
+		return ids[pos], nil
+	}
+	s, err := endpoint(b.attrs.S)
+	if err != nil {
+		return nil, nil, err
+	}
+	t, err := endpoint(b.attrs.T)
+	if err != nil {
+		return nil, nil, err
+	}
+	g := kstar.NewAdjacencyGraph(n, s, t)
+
+	for pos, attrs := range b.nodeAttrs {
+		val, ok := attrs[b.attrs.Heuristic]
+		if !ok {
+			continue
+		}
+		h, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, nil, fmt.Errorf("node %q: %v", b.names[pos], err)
+		}
+		g.SetFValue(ids[pos], h)
+	}
+
+	edges, err := b.sortedEdges(ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, e := range edges {
+		val, ok := e.attrs[b.attrs.Cost]
+		if !ok {
+			return nil, nil, b.edgeErr(e, fmt.Errorf("missing %s attribute", b.attrs.Cost))
+		}
+		cost, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, nil, b.edgeErr(e, err)
+		}
+		if cost <= 0 {
+			return nil, nil, b.edgeErr(e, fmt.Errorf("cost %g is not strictly positive", cost))
+		}
+		g.AddEdge(ids[e.u], ids[e.v], cost)
+	}
+	return g, names, nil
+}
+
+// ids returns the id of the node at each position.
+func (b *builder) ids() []int {
+	ids := make([]int, len(b.names))
+	seen := make(map[int]bool, len(ids))
+	for pos, name := range b.names {
+		num := strings.TrimPrefix(name, "n")
+		id, err := strconv.Atoi(num)
+		if err != nil || id < 0 || strconv.Itoa(id) != num || seen[id] {
+			for pos := range ids {
+				ids[pos] = pos
+			}
+			return ids
+		}
+		seen[id] = true
+		ids[pos] = id
+	}
+	return ids
+}
+
+// sortedEdges returns the edges ordered so that parallel edges are added by ascending index.
+func (b *builder) sortedEdges(ids []int) ([]rawEdge, error) {
+	type indexedEdge struct {
+		rawEdge
+		index    float64
+		hasIndex bool
+	}
+	edges := make([]indexedEdge, len(b.edges))
+	for i, e := range b.edges {
+		edges[i].rawEdge = e
+		if val, ok := e.attrs[b.attrs.Index]; ok {
+			index, err := strconv.ParseFloat(val, 64)
+			if err != nil {
+				return nil, b.edgeErr(e, err)
+			}
+			edges[i].index, edges[i].hasIndex = index, true
+		}
+	}
+	sort.SliceStable(edges, func(i, j int) bool {
+		ei, ej := edges[i], edges[j]
+		switch {
+		case ids[ei.u] != ids[ej.u]:
+			return ids[ei.u] < ids[ej.u]
+		case ids[ei.v] != ids[ej.v]:
+			return ids[ei.v] < ids[ej.v]
+		case ei.hasIndex != ej.hasIndex:
+			return ei.hasIndex
+		}
+		return ei.index < ej.index
+	})
+
+	sorted := make([]rawEdge, len(edges))
+	for i, e := range edges {
+		if i > 0 && e.hasIndex && edges[i-1].hasIndex && e.index == edges[i-1].index &&
+			ids[e.u] == ids[edges[i-1].u] && ids[e.v] == ids[edges[i-1].v] {
+			return nil, b.edgeErr(e.rawEdge, fmt.Errorf("duplicate %s %g", b.attrs.Index, e.index))
+		}
+		sorted[i] = e.rawEdge
+	}
+	return sorted, nil
+}
+
+func (b *builder) edgeErr(e rawEdge, err error) error {
+	err = fmt.Errorf("edge %q -> %q: %v", b.names[e.u], b.names[e.v], err)
+	if e.line > 0 {
+		return &ParseError{Line: e.line, Err: err}
+	}
+	return err
+}
+
+func copyAttrs(attrs map[string]string) map[string]string {
+	cp := make(map[string]string, len(attrs))
+	for name, val := range attrs {
+		cp[name] = val
+	}
+	return cp
+}
+
+// palette holds the colours of the highlighted paths, reused when there are more paths than colours.
+var palette = []string{"#e41a1c", "#377eb8", "#4daf4a", "#984ea3", "#ff7f00", "#a65628", "#f781bf", "#999999"}
+
+// highlight holds what the exporters need to draw the paths returned by K* over a graph.
+type highlight struct {
+	costs []float64
+	// paths holds the positions in costs of the paths using each edge, in ascending order.
+	paths map[kstar.Edge][]int
+}
+
+func newHighlight(g *kstar.AdjacencyGraph, paths [][]kstar.Edge) highlight {
+	hl := highlight{costs: make([]float64, len(paths)), paths: make(map[kstar.Edge][]int)}
+	for p, path := range paths {
+		for _, e := range path {
+			hl.costs[p] += g.EdgeCost(e.U, e.V, e.I)
+			if ps := hl.paths[e]; len(ps) == 0 || ps[len(ps)-1] != p {
+				hl.paths[e] = append(ps, p)
+			}
+		}
+	}
+	return hl
+}
+
+func (hl highlight) color(p int) string {
+	return palette[p%len(palette)]
+}
+
+// label returns the rank and the cost of path p.
+func (hl highlight) label(p int) string {
+	return fmt.Sprintf("#%d (%s)", p+1, formatFloat(hl.costs[p]))
+}
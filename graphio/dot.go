@@ -0,0 +1,579 @@
+package graphio
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/jcasado94/kstar"
+)
+
+// DOT reads and writes graphs in the Graphviz DOT language.
+//
+// The reader understands directed and undirected graphs, the latter getting an edge in each direction, node and
+// edge statements, edge chains, default attribute statements and subgraphs, also as edge endpoints. Ports and
+// any attribute not named by Attributes are ignored.
+type DOT struct {
+	Attributes
+}
+
+// ReadDOT reads a graph in the DOT language with the default attributes.
+func ReadDOT(r io.Reader) (*kstar.AdjacencyGraph, []string, error) {
+	return DOT{}.Read(r)
+}
+
+// WriteDOT writes g in the DOT language with the default attributes, highlighting paths.
+func WriteDOT(w io.Writer, g *kstar.AdjacencyGraph, paths [][]kstar.Edge) error {
+	return DOT{}.Write(w, g, paths)
+}
+
+// Read reads a graph in the DOT language and returns it along with the name of each of its nodes, see Attributes
+// for how names map to node ids. Syntax errors are returned as *ParseError.
+func (f DOT) Read(r io.Reader) (*kstar.AdjacencyGraph, []string, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	p := &dotParser{lex: &dotLexer{src: string(src), line: 1}, b: newBuilder(f.Attributes)}
+	if err := p.parseGraph(); err != nil {
+		return nil, nil, err
+	}
+	return p.b.build()
+}
+
+// Write writes g in the DOT language. Edges are labelled with their cost, and the ones in paths are drawn in the
+// colour of each path running through them and labelled with its rank and cost. The graph label lists the paths.
+// Reading the output back gives a graph identical to g.
+func (f DOT) Write(w io.Writer, g *kstar.AdjacencyGraph, paths [][]kstar.Edge) error {
+	attrs := f.Attributes.withDefaults()
+	hl := newHighlight(g, paths)
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "digraph kstar {\n")
+	fmt.Fprintf(bw, "\t%s=%d;\n\t%s=%d;\n", dotID(attrs.S), g.S(), dotID(attrs.T), g.T())
+	if len(paths) > 0 {
+		var legend strings.Builder
+		for p := range paths {
+			fmt.Fprintf(&legend, "<font color=\"%s\">%s</font><br/>", hl.color(p), hl.label(p))
+		}
+		fmt.Fprintf(bw, "\tlabel=<%s>;\n\tlabelloc=t;\n", legend.String())
+	}
+
+	for _, n := range g.Nodes() {
+		nodeAttrs := make([]string, 0, 2)
+		if n == g.S() || n == g.T() {
+			nodeAttrs = append(nodeAttrs, "shape=doublecircle")
+		}
+		if h, ok := g.Heuristic(n); ok {
+			nodeAttrs = append(nodeAttrs, fmt.Sprintf("%s=%s", dotID(attrs.Heuristic), dotID(formatFloat(h))))
+		}
+		fmt.Fprintf(bw, "\t%d%s;\n", n, dotAttrList(nodeAttrs))
+	}
+
+	for _, e := range g.Edges() {
+		cost := formatFloat(g.EdgeCost(e.U, e.V, e.I))
+		edgeAttrs := []string{fmt.Sprintf("%s=%s", dotID(attrs.Cost), dotID(cost))}
+		label := []string{cost}
+		if ps := hl.paths[e]; len(ps) > 0 {
+			colors := make([]string, len(ps))
+			for i, p := range ps {
+				colors[i] = hl.color(p)
+				label = append(label, hl.label(p))
+			}
+			edgeAttrs = append(edgeAttrs, fmt.Sprintf("color=%q", strings.Join(colors, ":")), "penwidth=2")
+		}
+		edgeAttrs = append(edgeAttrs, fmt.Sprintf("label=%s", dotID(strings.Join(label, `\n`))))
+		fmt.Fprintf(bw, "\t%d -> %d%s;\n", e.U, e.V, dotAttrList(edgeAttrs))
+	}
+
+	fmt.Fprintf(bw, "}\n")
+	return bw.Flush()
+}
+
+var dotPlainID = regexp.MustCompile(`^([A-Za-z_][A-Za-z_0-9]*|-?(\.[0-9]+|[0-9]+(\.[0-9]*)?))$`)
+
+// dotID returns s as a DOT ID, quoting it if needed.
+func dotID(s string) string {
+	if dotPlainID.MatchString(s) && !dotKeywords[strings.ToLower(s)] {
+		return s
+	}
+	return `"` + strings.Replace(s, `"`, `\"`, -1) + `"`
+}
+
+func dotAttrList(attrs []string) string {
+	if len(attrs) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(attrs, ", ") + "]"
+}
+
+var dotKeywords = map[string]bool{"strict": true, "graph": true, "digraph": true, "node": true, "edge": true, "subgraph": true}
+
+type dotTokenKind int
+
+const (
+	dotEOF dotTokenKind = iota
+	dotIDToken
+	dotPunct
+)
+
+type dotToken struct {
+	kind dotTokenKind
+	val  string
+	// quoted is set for quoted and HTML strings, which are never keywords.
+	quoted bool
+	line   int
+}
+
+func (t dotToken) is(punct string) bool {
+	return t.kind == dotPunct && t.val == punct
+}
+
+func (t dotToken) keyword(kw string) bool {
+	return t.kind == dotIDToken && !t.quoted && strings.EqualFold(t.val, kw)
+}
+
+func (t dotToken) String() string {
+	switch t.kind {
+	case dotEOF:
+		return "end of input"
+	case dotIDToken:
+		return fmt.Sprintf("%q", t.val)
+	}
+	return t.val
+}
+
+type dotLexer struct {
+	src  string
+	pos  int
+	line int
+	peek *dotToken
+}
+
+func (l *dotLexer) errorf(format string, args ...interface{}) error {
+	return &ParseError{Line: l.line, Err: fmt.Errorf(format, args...)}
+}
+
+// next returns the next token.
+func (l *dotLexer) next() (dotToken, error) {
+	if l.peek != nil {
+		t := *l.peek
+		l.peek = nil
+		return t, nil
+	}
+	if err := l.skipSpace(); err != nil {
+		return dotToken{}, err
+	}
+	if l.pos == len(l.src) {
+		return dotToken{kind: dotEOF, line: l.line}, nil
+	}
+
+	c := l.src[l.pos]
+	line := l.line
+	switch {
+	case c == '"':
+		val, err := l.quoted()
+		return dotToken{kind: dotIDToken, val: val, quoted: true, line: line}, err
+	case c == '<':
+		val, err := l.html()
+		return dotToken{kind: dotIDToken, val: val, quoted: true, line: line}, err
+	case strings.HasPrefix(l.src[l.pos:], "->"), strings.HasPrefix(l.src[l.pos:], "--"):
+		l.pos += 2
+		return dotToken{kind: dotPunct, val: l.src[l.pos-2 : l.pos], line: line}, nil
+	case strings.IndexByte("{}[];,=:", c) >= 0:
+		l.pos++
+		return dotToken{kind: dotPunct, val: string(c), line: line}, nil
+	case c == '-' || c == '.' || c >= '0' && c <= '9':
+		return dotToken{kind: dotIDToken, val: l.numeral(), line: line}, nil
+	case isDotIDByte(c):
+		start := l.pos
+		for l.pos < len(l.src) && (isDotIDByte(l.src[l.pos]) || l.src[l.pos] >= '0' && l.src[l.pos] <= '9') {
+			l.pos++
+		}
+		return dotToken{kind: dotIDToken, val: l.src[start:l.pos], line: line}, nil
+	}
+	return dotToken{}, l.errorf("unexpected character %q", c)
+}
+
+// unread makes t the next token.
+func (l *dotLexer) unread(t dotToken) {
+	l.peek = &t
+}
+
+func isDotIDByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+// skipSpace skips white space, comments and lines starting with #.
+func (l *dotLexer) skipSpace() error {
+	lineStart := l.pos == 0
+	for l.pos < len(l.src) {
+		switch c := l.src[l.pos]; {
+		case c == '\n':
+			l.line++
+			l.pos++
+			lineStart = true
+		case c == ' ' || c == '\t' || c == '\r':
+			l.pos++
+		case c == '#' && lineStart, strings.HasPrefix(l.src[l.pos:], "//"):
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.pos++
+			}
+		case strings.HasPrefix(l.src[l.pos:], "/*"):
+			end := strings.Index(l.src[l.pos+2:], "*/")
+			if end < 0 {
+				return l.errorf("unterminated comment")
+			}
+			l.line += strings.Count(l.src[l.pos:l.pos+2+end], "\n")
+			l.pos += end + 4
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// quoted reads a double quoted string, unescaping quotes and removing escaped line breaks.
+func (l *dotLexer) quoted() (string, error) {
+	var sb strings.Builder
+	line := l.line
+	for l.pos++; l.pos < len(l.src); l.pos++ {
+		c := l.src[l.pos]
+		switch {
+		case c == '"':
+			l.pos++
+			return sb.String(), nil
+		case c == '\\' && l.pos+1 < len(l.src) && l.src[l.pos+1] == '"':
+			sb.WriteByte('"')
+			l.pos++
+		case c == '\\' && l.pos+1 < len(l.src) && l.src[l.pos+1] == '\n':
+			l.line++
+			l.pos++
+		default:
+			if c == '\n' {
+				l.line++
+			}
+			sb.WriteByte(c)
+		}
+	}
+	return "", &ParseError{Line: line, Err: errors.New("unterminated string")}
+}
+
+// html reads an HTML string, returning what is between its outer angle brackets.
+func (l *dotLexer) html() (string, error) {
+	start, line := l.pos+1, l.line
+	depth := 0
+	for ; l.pos < len(l.src); l.pos++ {
+		switch l.src[l.pos] {
+		case '<':
+			depth++
+		case '>':
+			depth--
+			if depth == 0 {
+				l.pos++
+				return l.src[start : l.pos-1], nil
+			}
+		case '\n':
+			l.line++
+		}
+	}
+	return "", &ParseError{Line: line, Err: errors.New("unterminated HTML string")}
+}
+
+func (l *dotLexer) numeral() string {
+	start := l.pos
+	if l.src[l.pos] == '-' {
+		l.pos++
+	}
+	for l.pos < len(l.src) && (l.src[l.pos] == '.' || l.src[l.pos] >= '0' && l.src[l.pos] <= '9') {
+		l.pos++
+	}
+	return l.src[start:l.pos]
+}
+
+// dotScope holds the default attributes set by the statements of a graph or subgraph.
+type dotScope struct {
+	node, edge map[string]string
+}
+
+type dotParser struct {
+	lex      *dotLexer
+	b        *builder
+	directed bool
+}
+
+func (p *dotParser) expect(punct string) error {
+	t, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	if !t.is(punct) {
+		return &ParseError{Line: t.line, Err: fmt.Errorf("expected %s, found %s", punct, t)}
+	}
+	return nil
+}
+
+// parseGraph parses [strict] (graph | digraph) [ID] { stmt_list }.
+func (p *dotParser) parseGraph() error {
+	t, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	if t.keyword("strict") {
+		if t, err = p.lex.next(); err != nil {
+			return err
+		}
+	}
+	switch {
+	case t.keyword("digraph"):
+		p.directed = true
+	case t.keyword("graph"):
+	default:
+		return &ParseError{Line: t.line, Err: fmt.Errorf("expected graph or digraph, found %s", t)}
+	}
+	if t, err = p.lex.next(); err != nil {
+		return err
+	}
+	if t.kind != dotIDToken {
+		p.lex.unread(t)
+	}
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	root := dotScope{node: map[string]string{}, edge: map[string]string{}}
+	if _, err := p.parseStmts(root, true); err != nil {
+		return err
+	}
+	if t, err = p.lex.next(); err != nil {
+		return err
+	}
+	if t.kind != dotEOF {
+		return &ParseError{Line: t.line, Err: fmt.Errorf("unexpected %s after the graph", t)}
+	}
+	return nil
+}
+
+// parseStmts parses statements up to the closing brace, returning the nodes that appeared in them.
+// Graph attributes are only kept at the top level.
+func (p *dotParser) parseStmts(scope dotScope, top bool) ([]int, error) {
+	var nodes []int
+	for {
+		t, err := p.lex.next()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case t.is("}"):
+			return nodes, nil
+		case t.is(";"):
+			continue
+		case t.kind == dotEOF:
+			return nil, &ParseError{Line: t.line, Err: errors.New("missing }")}
+		case t.keyword("graph"), t.keyword("node"), t.keyword("edge"):
+			attrs, err := p.parseAttrLists(true)
+			if err != nil {
+				return nil, err
+			}
+			switch {
+			case t.keyword("node"):
+				scope.node = merge(scope.node, attrs)
+			case t.keyword("edge"):
+				scope.edge = merge(scope.edge, attrs)
+			case top:
+				p.b.graphAttrs = merge(p.b.graphAttrs, attrs)
+			}
+			continue
+		}
+
+		if t.kind == dotIDToken {
+			eq, err := p.lex.next()
+			if err != nil {
+				return nil, err
+			}
+			if eq.is("=") {
+				val, err := p.lex.next()
+				if err != nil {
+					return nil, err
+				}
+				if val.kind != dotIDToken {
+					return nil, &ParseError{Line: val.line, Err: fmt.Errorf("expected a value, found %s", val)}
+				}
+				if top {
+					p.b.graphAttrs[t.val] = val.val
+				}
+				continue
+			}
+			p.lex.unread(eq)
+		}
+
+		stmtNodes, err := p.parseNodeOrEdgeStmt(t, scope)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, stmtNodes...)
+	}
+}
+
+// parseNodeOrEdgeStmt parses a node statement or an edge chain starting with t.
+func (p *dotParser) parseNodeOrEdgeStmt(t dotToken, scope dotScope) ([]int, error) {
+	line := t.line
+	first, isNode, err := p.parseEndpoint(t, scope)
+	if err != nil {
+		return nil, err
+	}
+	endpoints := [][]int{first}
+	for {
+		op, err := p.lex.next()
+		if err != nil {
+			return nil, err
+		}
+		if !op.is("->") && !op.is("--") {
+			p.lex.unread(op)
+			break
+		}
+		if op.is("->") != p.directed {
+			return nil, &ParseError{Line: op.line, Err: fmt.Errorf("edge operator %s does not match the graph type", op.val)}
+		}
+		next, err := p.lex.next()
+		if err != nil {
+			return nil, err
+		}
+		nodes, _, err := p.parseEndpoint(next, scope)
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, nodes)
+	}
+
+	attrs, err := p.parseAttrLists(false)
+	if err != nil {
+		return nil, err
+	}
+	var nodes []int
+	for _, ns := range endpoints {
+		nodes = append(nodes, ns...)
+	}
+	if len(endpoints) == 1 {
+		if isNode {
+			p.b.setNodeAttrs(first[0], attrs)
+		}
+		return nodes, nil
+	}
+	edgeAttrs := merge(scope.edge, attrs)
+	for i := 1; i < len(endpoints); i++ {
+		for _, u := range endpoints[i-1] {
+			for _, v := range endpoints[i] {
+				p.b.edge(u, v, edgeAttrs, p.directed, line)
+			}
+		}
+	}
+	return nodes, nil
+}
+
+// parseEndpoint parses a node id, with an optional port, or a subgraph, returning its nodes and whether it was a
+// node id.
+func (p *dotParser) parseEndpoint(t dotToken, scope dotScope) ([]int, bool, error) {
+	if t.keyword("subgraph") || t.is("{") {
+		nodes, err := p.parseSubgraph(t, scope)
+		return nodes, false, err
+	}
+	if t.kind != dotIDToken {
+		return nil, false, &ParseError{Line: t.line, Err: fmt.Errorf("unexpected %s", t)}
+	}
+	n := p.b.node(t.val, scope.node)
+	// ports, as in n:port:compass, do not matter for the graph
+	for {
+		colon, err := p.lex.next()
+		if err != nil {
+			return nil, false, err
+		}
+		if !colon.is(":") {
+			p.lex.unread(colon)
+			return []int{n}, true, nil
+		}
+		port, err := p.lex.next()
+		if err != nil {
+			return nil, false, err
+		}
+		if port.kind != dotIDToken {
+			return nil, false, &ParseError{Line: port.line, Err: fmt.Errorf("expected a port, found %s", port)}
+		}
+	}
+}
+
+// parseSubgraph parses [subgraph [ID]] { stmt_list }, starting with t. Its default attributes only apply inside it.
+func (p *dotParser) parseSubgraph(t dotToken, scope dotScope) ([]int, error) {
+	if t.keyword("subgraph") {
+		next, err := p.lex.next()
+		if err != nil {
+			return nil, err
+		}
+		if next.kind == dotIDToken {
+			if next, err = p.lex.next(); err != nil {
+				return nil, err
+			}
+		}
+		if !next.is("{") {
+			return nil, &ParseError{Line: next.line, Err: fmt.Errorf("expected {, found %s", next)}
+		}
+	}
+	inner := dotScope{node: merge(scope.node, nil), edge: merge(scope.edge, nil)}
+	return p.parseStmts(inner, false)
+}
+
+// parseAttrLists parses [ a_list ] [ a_list ] ..., which must be present if required.
+func (p *dotParser) parseAttrLists(required bool) (map[string]string, error) {
+	attrs := make(map[string]string)
+	for {
+		t, err := p.lex.next()
+		if err != nil {
+			return nil, err
+		}
+		if !t.is("[") {
+			if required {
+				return nil, &ParseError{Line: t.line, Err: fmt.Errorf("expected [, found %s", t)}
+			}
+			p.lex.unread(t)
+			return attrs, nil
+		}
+		required = false
+		for {
+			name, err := p.lex.next()
+			if err != nil {
+				return nil, err
+			}
+			if name.is("]") {
+				break
+			}
+			if name.is(";") || name.is(",") {
+				continue
+			}
+			if name.kind != dotIDToken {
+				return nil, &ParseError{Line: name.line, Err: fmt.Errorf("expected an attribute, found %s", name)}
+			}
+			if err := p.expect("="); err != nil {
+				return nil, err
+			}
+			val, err := p.lex.next()
+			if err != nil {
+				return nil, err
+			}
+			if val.kind != dotIDToken {
+				return nil, &ParseError{Line: val.line, Err: fmt.Errorf("expected a value, found %s", val)}
+			}
+			attrs[name.val] = val.val
+		}
+	}
+}
+
+// merge returns a copy of attrs with the values of over.
+func merge(attrs, over map[string]string) map[string]string {
+	merged := copyAttrs(attrs)
+	for name, val := range over {
+		merged[name] = val
+	}
+	return merged
+}
@@ -0,0 +1,125 @@
+package graphio
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jcasado94/kstar"
+)
+
+func TestDOTRoundTrip(t *testing.T) {
+	for _, name := range []string{"5.1", "6.1", "7.1"} {
+		g, err := ReadFile(datasetPath + name + ".graph")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := WriteDOT(&buf, g, kstar.Run(g, 5)); err != nil {
+			t.Fatal(err)
+		}
+		rg, _, err := ReadDOT(&buf)
+		if err != nil {
+			t.Errorf("Reading back %s failed: %v", name, err)
+			continue
+		}
+		assertSameGraph(t, name, g, rg)
+	}
+}
+
+func TestReadDOT(t *testing.T) {
+	input := `/* a small road map */
+strict digraph roads {
+	s = home; t = "work"
+	edge [weight = 1]
+	# a preprocessor line
+	home [h=2]
+	home -> shop:north -> work [weight=3, index=1]
+	home -> shop [weight=2, index=0] // the parallel edge
+	subgraph cluster { edge [weight=5]; home -> { work park } }
+	park -> work
+}`
+	g, names, err := DOT{Attributes{Cost: "weight"}}.Read(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make(map[string]int)
+	for id, name := range names {
+		ids[name] = id
+	}
+	home, shop, work, park := ids["home"], ids["shop"], ids["work"], ids["park"]
+	if len(names) != 4 || g.S() != home || g.T() != work || g.FValue(home) != 2 {
+		t.Fatalf("Unexpected nodes %v, S %d or T %d.", names, g.S(), g.T())
+	}
+	expected := map[kstar.Edge]float64{
+		{U: home, V: shop, I: 0}: 2,
+		{U: home, V: shop, I: 1}: 3,
+		{U: shop, V: work, I: 0}: 3,
+		{U: home, V: work, I: 0}: 5,
+		{U: home, V: park, I: 0}: 5,
+		{U: park, V: work, I: 0}: 1,
+	}
+	if g.NumEdges() != len(expected) {
+		t.Errorf("Expected %d edges, but found %d.", len(expected), g.NumEdges())
+	}
+	for e, cost := range expected {
+		if conns := g.Connections(e.U); len(conns[e.V]) <= e.I || g.EdgeCost(e.U, e.V, e.I) != cost {
+			t.Errorf("Expected edge %v to cost %g.", e, cost)
+		}
+	}
+
+	g, _, err = ReadDOT(strings.NewReader("graph { 3 -- 1 [cost=2] }"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.NumNodes() != 4 || g.NumEdges() != 2 || g.EdgeCost(1, 3, 0) != 2 {
+		t.Errorf("Expected the undirected edge 1 -- 3 to become two edges between nodes 1 and 3.")
+	}
+}
+
+func TestReadDOTErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		line  int
+	}{
+		{"digraph {\n a -> b\n}", 2},
+		{"digraph {\n a -- b [cost=1]\n}", 2},
+		{"digraph {\n\n a -> b [cost=x]\n}", 3},
+		{"digraph {\n a -> b [cost=0]\n}", 2},
+		{"digraph {\n a -> b [cost=1]\n", 3},
+		{"digraph {\n a [h=1 cost]\n}", 2},
+		{"graph {\n \"a -- b\n}", 2},
+		{"digraph {\n a -> b [cost=1, index=0]\n a -> b [cost=2, index=0]\n}", 3},
+	}
+	for _, test := range tests {
+		_, _, err := ReadDOT(strings.NewReader(test.input))
+		var pe *ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("Expected a parse error reading %q, but found %v.", test.input, err)
+		} else if pe.Line != test.line {
+			t.Errorf("Expected an error at line %d reading %q, but found %v.", test.line, test.input, err)
+		}
+	}
+}
+
+func TestWriteDOTHighlight(t *testing.T) {
+	g := kstar.NewAdjacencyGraph(3, 0, 2)
+	g.AddEdge(0, 1, 1)
+	g.AddEdge(1, 2, 1)
+	g.AddEdge(0, 2, 3)
+	var buf bytes.Buffer
+	if err := WriteDOT(&buf, g, kstar.Run(g, 2)); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, line := range []string{
+		`0 -> 1 [cost=1, color="#e41a1c", penwidth=2, label="1\n#1 (2)"];`,
+		`0 -> 2 [cost=3, color="#377eb8", penwidth=2, label="3\n#2 (3)"];`,
+		`<font color="#377eb8">#2 (3)</font>`,
+	} {
+		if !strings.Contains(out, line) {
+			t.Errorf("Expected %q in the output:\n%s", line, out)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+// Package graphio reads and writes graphs for K* in several text formats.
+//
+// The .graph format, used by the datasets of this repository, is line based:
+//
+//	p <n> <m> <s> <t>
+//	h <node> <heuristic value>
+//	e <u> <v> <cost>
+//
+// The p line comes first and declares n nodes, numbered 0 to n-1, m edges and the departure and arrival nodes.
+// Each h line sets the heuristic value of a node, at most once per node, and each e line adds an edge. Parallel
+// edges are numbered in the order they appear. Blank lines and lines starting with c or # are ignored.
+package graphio
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jcasado94/kstar"
+)
+
+const (
+	commentMark   = "c"
+	problemMark   = "p"
+	heuristicMark = "h"
+	edgeMark      = "e"
+)
+
+// ParseError reports an error found at a line of the input.
+type ParseError struct {
+	Line int
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ReadFile reads a graph in the .graph format from the file at path.
+func ReadFile(path string) (*kstar.AdjacencyGraph, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	g, err := Read(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return g, nil
+}
+
+// Read reads a graph in the .graph format. Errors in the input are returned as *ParseError.
+func Read(r io.Reader) (*kstar.AdjacencyGraph, error) {
+	var g *kstar.AdjacencyGraph
+	var n, m int
+
+	line := 0
+	lineErr := func(format string, args ...interface{}) error {
+		return &ParseError{Line: line, Err: fmt.Errorf(format, args...)}
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line++
+		vals := strings.Fields(scanner.Text())
+		if len(vals) == 0 || vals[0] == commentMark || strings.HasPrefix(vals[0], "#") {
+			continue
+		}
+
+		if vals[0] != problemMark && g == nil {
+			return nil, lineErr("%q line before the p line", vals[0])
+		}
+
+		switch vals[0] {
+		case problemMark:
+			if g != nil {
+				return nil, lineErr("duplicate p line")
+			}
+			ints, err := parseInts(vals, 4)
+			if err != nil {
+				return nil, &ParseError{Line: line, Err: err}
+			}
+			var s, t int
+			n, m, s, t = ints[0], ints[1], ints[2], ints[3]
+			if n <= 0 || m < 0 {
+				return nil, lineErr("invalid node count %d or edge count %d", n, m)
+			}
+			if !inRange(s, n) || !inRange(t, n) {
+				return nil, lineErr("s %d or t %d out of range [0, %d)", s, t, n)
+			}
+			g = kstar.NewAdjacencyGraph(n, s, t)
+
+		case heuristicMark:
+			if len(vals) != 3 {
+				return nil, lineErr("expected 2 values, found %d", len(vals)-1)
+			}
+			node, err := strconv.Atoi(vals[1])
+			if err != nil {
+				return nil, &ParseError{Line: line, Err: err}
+			}
+			h, err := strconv.ParseFloat(vals[2], 64)
+			if err != nil {
+				return nil, &ParseError{Line: line, Err: err}
+			}
+			if !inRange(node, n) {
+				return nil, lineErr("node %d out of range [0, %d)", node, n)
+			}
+			if _, ok := g.Heuristic(node); ok {
+				return nil, lineErr("duplicate heuristic value for node %d", node)
+			}
+			g.SetFValue(node, h)
+
+		case edgeMark:
+			if len(vals) != 4 {
+				return nil, lineErr("expected 3 values, found %d", len(vals)-1)
+			}
+			ints, err := parseInts(vals[:3], 2)
+			if err != nil {
+				return nil, &ParseError{Line: line, Err: err}
+			}
+			cost, err := strconv.ParseFloat(vals[3], 64)
+			if err != nil {
+				return nil, &ParseError{Line: line, Err: err}
+			}
+			u, v := ints[0], ints[1]
+			if !inRange(u, n) || !inRange(v, n) {
+				return nil, lineErr("edge %d %d out of range [0, %d)", u, v, n)
+			}
+			if cost <= 0 {
+				return nil, lineErr("edge cost %g is not strictly positive", cost)
+			}
+			if g.NumEdges() == m {
+				return nil, lineErr("more than the %d declared edges", m)
+			}
+			g.AddEdge(u, v, cost)
+
+		default:
+			return nil, lineErr("unknown line type %q", vals[0])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if g == nil {
+		return nil, errors.New("missing p line")
+	}
+	if g.NumEdges() != m {
+		return nil, &ParseError{Line: line, Err: fmt.Errorf("found %d edges, but %d were declared", g.NumEdges(), m)}
+	}
+	return g, nil
+}
+
+// Write writes g in the .graph format, heuristic values and edges sorted by node. Reading the output back gives the
+// same nodes, edges, costs and heuristic values, as long as every edge cost of g is strictly positive.
+func Write(w io.Writer, g *kstar.AdjacencyGraph) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%s %d %d %d %d\n", problemMark, g.NumNodes(), g.NumEdges(), g.S(), g.T())
+	for _, node := range g.HeuristicNodes() {
+		h, _ := g.Heuristic(node)
+		fmt.Fprintf(bw, "%s %d %s\n", heuristicMark, node, formatFloat(h))
+	}
+	for _, e := range g.Edges() {
+		fmt.Fprintf(bw, "%s %d %d %s\n", edgeMark, e.U, e.V, formatFloat(g.EdgeCost(e.U, e.V, e.I)))
+	}
+	return bw.Flush()
+}
+
+func parseInts(vals []string, n int) ([]int, error) {
+	if len(vals) != n+1 {
+		return nil, fmt.Errorf("expected %d values, found %d", n, len(vals)-1)
+	}
+	ints := make([]int, n)
+	for i, val := range vals[1:] {
+		num, err := strconv.Atoi(val)
+		if err != nil {
+			return nil, err
+		}
+		ints[i] = num
+	}
+	return ints, nil
+}
+
+func inRange(node, n int) bool {
+	return node >= 0 && node < n
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
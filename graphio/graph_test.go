@@ -0,0 +1,88 @@
+package graphio
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jcasado94/kstar"
+)
+
+const datasetPath = "../datasets/graph/"
+
+func TestReadDatasets(t *testing.T) {
+	for _, name := range []string{"5.1", "5.2", "6.1", "7.1", "7.2", "5881.1"} {
+		g, err := ReadFile(datasetPath + name + ".graph")
+		if err != nil {
+			t.Errorf("Reading %s failed: %v", name, err)
+			continue
+		}
+
+		var buf bytes.Buffer
+		if err := Write(&buf, g); err != nil {
+			t.Fatal(err)
+		}
+		rg, err := Read(&buf)
+		if err != nil {
+			t.Errorf("Reading back %s failed: %v", name, err)
+			continue
+		}
+		assertSameGraph(t, name, g, rg)
+	}
+}
+
+func assertSameGraph(t *testing.T, name string, g, rg *kstar.AdjacencyGraph) {
+	if rg.S() != g.S() || rg.T() != g.T() || rg.NumNodes() != g.NumNodes() {
+		t.Errorf("%s: round trip changed the problem line.", name)
+	}
+	if !reflect.DeepEqual(rg.Edges(), g.Edges()) {
+		t.Errorf("%s: round trip changed the edges.", name)
+	}
+	for _, e := range g.Edges() {
+		if rg.EdgeCost(e.U, e.V, e.I) != g.EdgeCost(e.U, e.V, e.I) {
+			t.Errorf("%s: round trip changed the cost of %v.", name, e)
+		}
+	}
+	for _, n := range g.Nodes() {
+		if rg.FValue(n) != g.FValue(n) {
+			t.Errorf("%s: round trip changed the heuristic value of %d.", name, n)
+		}
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		line  int
+	}{
+		{"p 2 1 0 1\n\ne 0 1 1\ne 1 0 1\n", 4},
+		{"e 0 1 1\n", 1},
+		{"p 2 1 0 1\nh 0 1\nh 0 2\ne 0 1 1\n", 3},
+		{"p 2 1 0 2\n", 1},
+		{"p 2 1 0 1\ne 0 5 1\n", 2},
+		{"p 2 1 0 1\nh 2 1\ne 0 1 1\n", 2},
+		{"p 2 1 0 1\ne 0 1 0\n", 2},
+		{"p 2 2 0 1\n# comment\ne 0 1 1\n", 3},
+		{"p 2 1 0 1\ne 0 1\n", 2},
+		{"p 2 1 0 1\nx 0 1\n", 2},
+	}
+	for _, test := range tests {
+		_, err := Read(strings.NewReader(test.input))
+		var pe *ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("Expected a parse error reading %q, but found %v.", test.input, err)
+		} else if pe.Line != test.line {
+			t.Errorf("Expected an error at line %d reading %q, but found %v.", test.line, test.input, err)
+		}
+	}
+
+	if _, err := Read(strings.NewReader("c empty\n")); err == nil {
+		t.Error("Expected an error reading a graph without p line.")
+	}
+
+	if _, err := Read(strings.NewReader("p 5882 1 0 1\ne 0 1 1\n")); err != nil {
+		t.Errorf("Strict read failed: %v", err)
+	}
+}
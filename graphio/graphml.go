@@ -0,0 +1,213 @@
+package graphio
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jcasado94/kstar"
+)
+
+const graphMLNamespace = "http://graphml.graphdrawing.org/xmlns"
+
+// GraphML reads and writes graphs in the GraphML format.
+//
+// The reader takes the first graph of the file, whose nodes are named by their id. Data elements are matched to
+// Attributes by the attr.name of their key, or by the key id if it was not declared, and keys with a default value
+// apply to the elements without data for them. Undirected edges get an edge in each direction. Nested graphs and
+// hyperedges are ignored.
+type GraphML struct {
+	Attributes
+}
+
+type xmlGraphML struct {
+	XMLName xml.Name   `xml:"graphml"`
+	XMLNS   string     `xml:"xmlns,attr,omitempty"`
+	Keys    []xmlKey   `xml:"key"`
+	Graphs  []xmlGraph `xml:"graph"`
+}
+
+type xmlKey struct {
+	ID      string  `xml:"id,attr"`
+	For     string  `xml:"for,attr,omitempty"`
+	Name    string  `xml:"attr.name,attr,omitempty"`
+	Type    string  `xml:"attr.type,attr,omitempty"`
+	Default *string `xml:"default"`
+}
+
+type xmlGraph struct {
+	ID          string    `xml:"id,attr,omitempty"`
+	EdgeDefault string    `xml:"edgedefault,attr"`
+	Data        []xmlData `xml:"data"`
+	Nodes       []xmlNode `xml:"node"`
+	Edges       []xmlEdge `xml:"edge"`
+}
+
+type xmlNode struct {
+	ID   string    `xml:"id,attr"`
+	Data []xmlData `xml:"data"`
+}
+
+type xmlEdge struct {
+	Source   string    `xml:"source,attr"`
+	Target   string    `xml:"target,attr"`
+	Directed string    `xml:"directed,attr,omitempty"`
+	Data     []xmlData `xml:"data"`
+}
+
+type xmlData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// ReadGraphML reads a graph in the GraphML format with the default attributes.
+func ReadGraphML(r io.Reader) (*kstar.AdjacencyGraph, []string, error) {
+	return GraphML{}.Read(r)
+}
+
+// WriteGraphML writes g in the GraphML format with the default attributes, highlighting paths.
+func WriteGraphML(w io.Writer, g *kstar.AdjacencyGraph, paths [][]kstar.Edge) error {
+	return GraphML{}.Write(w, g, paths)
+}
+
+// Read reads a graph in the GraphML format and returns it along with the name of each of its nodes, see Attributes
+// for how names map to node ids.
+func (f GraphML) Read(r io.Reader) (*kstar.AdjacencyGraph, []string, error) {
+	var doc xmlGraphML
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, nil, err
+	}
+	if len(doc.Graphs) == 0 {
+		return nil, nil, errors.New("no graph found")
+	}
+	graph := doc.Graphs[0]
+
+	// names and defaults of the keys of each domain
+	names := make(map[string]map[string]string)
+	defaults := make(map[string]map[string]string)
+	for _, domain := range []string{"graph", "node", "edge"} {
+		names[domain] = make(map[string]string)
+		defaults[domain] = make(map[string]string)
+	}
+	for _, key := range doc.Keys {
+		name := key.Name
+		if name == "" {
+			name = key.ID
+		}
+		for domain := range names {
+			if key.For != domain && key.For != "all" && key.For != "" {
+				continue
+			}
+			names[domain][key.ID] = name
+			if key.Default != nil {
+				defaults[domain][name] = strings.TrimSpace(*key.Default)
+			}
+		}
+	}
+	attrs := func(domain string, data []xmlData) map[string]string {
+		values := copyAttrs(defaults[domain])
+		for _, d := range data {
+			name, ok := names[domain][d.Key]
+			if !ok {
+				name = d.Key
+			}
+			values[name] = strings.TrimSpace(d.Value)
+		}
+		return values
+	}
+
+	b := newBuilder(f.Attributes)
+	b.graphAttrs = attrs("graph", graph.Data)
+	for _, n := range graph.Nodes {
+		if _, ok := b.nodes[n.ID]; ok {
+			return nil, nil, fmt.Errorf("duplicate node %q", n.ID)
+		}
+		b.node(n.ID, attrs("node", n.Data))
+	}
+	for _, e := range graph.Edges {
+		u, ok := b.nodes[e.Source]
+		if !ok {
+			return nil, nil, fmt.Errorf("edge source %q not found", e.Source)
+		}
+		v, ok := b.nodes[e.Target]
+		if !ok {
+			return nil, nil, fmt.Errorf("edge target %q not found", e.Target)
+		}
+		directed := graph.EdgeDefault != "undirected"
+		if e.Directed != "" {
+			directed = e.Directed == "true"
+		}
+		b.edge(u, v, attrs("edge", e.Data), directed, 0)
+	}
+	return b.build()
+}
+
+// Write writes g in the GraphML format, naming node n as "n<n>". The edges in paths get a color data element with
+// the colour of the first path running through them and a label with the rank and cost of each of those paths.
+// Reading the output back gives a graph identical to g.
+func (f GraphML) Write(w io.Writer, g *kstar.AdjacencyGraph, paths [][]kstar.Edge) error {
+	attrs := f.Attributes.withDefaults()
+	hl := newHighlight(g, paths)
+	nodeName := func(n int) string {
+		return fmt.Sprintf("n%d", n)
+	}
+
+	doc := xmlGraphML{
+		XMLNS: graphMLNamespace,
+		Keys: []xmlKey{
+			{ID: attrs.S, For: "graph", Name: attrs.S, Type: "string"},
+			{ID: attrs.T, For: "graph", Name: attrs.T, Type: "string"},
+			{ID: attrs.Heuristic, For: "node", Name: attrs.Heuristic, Type: "double"},
+			{ID: attrs.Cost, For: "edge", Name: attrs.Cost, Type: "double"},
+		},
+	}
+	graph := xmlGraph{
+		ID:          "kstar",
+		EdgeDefault: "directed",
+		Data:        []xmlData{{Key: attrs.S, Value: nodeName(g.S())}, {Key: attrs.T, Value: nodeName(g.T())}},
+	}
+	if len(paths) > 0 {
+		doc.Keys = append(doc.Keys,
+			xmlKey{ID: "color", For: "edge", Name: "color", Type: "string"},
+			xmlKey{ID: "label", For: "edge", Name: "label", Type: "string"})
+	}
+
+	for _, n := range g.Nodes() {
+		node := xmlNode{ID: nodeName(n)}
+		if h, ok := g.Heuristic(n); ok {
+			node.Data = append(node.Data, xmlData{Key: attrs.Heuristic, Value: formatFloat(h)})
+		}
+		graph.Nodes = append(graph.Nodes, node)
+	}
+	for _, e := range g.Edges() {
+		edge := xmlEdge{
+			Source: nodeName(e.U),
+			Target: nodeName(e.V),
+			Data:   []xmlData{{Key: attrs.Cost, Value: formatFloat(g.EdgeCost(e.U, e.V, e.I))}},
+		}
+		if ps := hl.paths[e]; len(ps) > 0 {
+			labels := make([]string, len(ps))
+			for i, p := range ps {
+				labels[i] = hl.label(p)
+			}
+			edge.Data = append(edge.Data,
+				xmlData{Key: "color", Value: hl.color(ps[0])},
+				xmlData{Key: "label", Value: strings.Join(labels, ", ")})
+		}
+		graph.Edges = append(graph.Edges, edge)
+	}
+	doc.Graphs = []xmlGraph{graph}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
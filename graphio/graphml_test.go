@@ -0,0 +1,69 @@
+package graphio
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/jcasado94/kstar"
+)
+
+func TestGraphMLRoundTrip(t *testing.T) {
+	for _, name := range []string{"5.1", "6.1", "7.1"} {
+		g, err := ReadFile(datasetPath + name + ".graph")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := WriteGraphML(&buf, g, kstar.Run(g, 5)); err != nil {
+			t.Fatal(err)
+		}
+		rg, _, err := ReadGraphML(&buf)
+		if err != nil {
+			t.Errorf("Reading back %s failed: %v", name, err)
+			continue
+		}
+		assertSameGraph(t, name, g, rg)
+	}
+}
+
+func TestReadGraphML(t *testing.T) {
+	input := `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+  <key id="d0" for="edge" attr.name="weight" attr.type="double"><default>1</default></key>
+  <key id="d1" for="node" attr.name="h" attr.type="double"/>
+  <graph id="G" edgedefault="undirected">
+    <data key="s">a</data>
+    <data key="t">c</data>
+    <node id="a"><data key="d1">1.5</data></node>
+    <node id="b"/>
+    <node id="c"/>
+    <edge source="a" target="b"><data key="d0">2</data></edge>
+    <edge source="b" target="c" directed="true"/>
+  </graph>
+</graphml>`
+	g, names, err := GraphML{Attributes{Cost: "weight"}}.Read(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(names, " ") != "a b c" || g.S() != 0 || g.T() != 2 || g.FValue(0) != 1.5 {
+		t.Fatalf("Unexpected nodes %v, S %d or T %d.", names, g.S(), g.T())
+	}
+	if g.NumEdges() != 3 || g.EdgeCost(0, 1, 0) != 2 || g.EdgeCost(1, 0, 0) != 2 || g.EdgeCost(1, 2, 0) != 1 {
+		t.Errorf("Unexpected edges %v.", g.Edges())
+	}
+	if paths := kstar.Run(g, 1); len(paths) != 1 || len(paths[0]) != 2 {
+		t.Errorf("Expected a path of 2 edges, but found %v.", paths)
+	}
+
+	for _, input := range []string{
+		`<graphml></graphml>`,
+		`<graphml><graph><node id="a"/><edge source="a" target="b"/></graph></graphml>`,
+		`<graphml><graph><node id="a"/><node id="a"/></graph></graphml>`,
+		`<graphml><graph><node id="a"/><edge source="a" target="a"/></graph></graphml>`,
+	} {
+		if _, _, err := ReadGraphML(strings.NewReader(input)); err == nil {
+			t.Errorf("Expected an error reading %s.", input)
+		}
+	}
+}
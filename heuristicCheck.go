@@ -0,0 +1,149 @@
+package kstar
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+)
+
+// HeuristicReport lists the violations of admissibility and consistency of the heuristic of a Graph, found by
+// CheckHeuristic. Both lists are sorted by node and edge.
+type HeuristicReport struct {
+	// Nodes is the number of nodes reachable from S(), the ones checked.
+	Nodes int
+	// Overestimates are the nodes whose FValue exceeds their cost to T(). Any of them makes Run return wrong rankings.
+	Overestimates []Overestimate
+	// Inconsistencies are the edges whose cost plus the FValue of their end is below the FValue of their start. They
+	// make A* reopen nodes, but do not affect the results.
+	Inconsistencies []Inconsistency
+}
+
+// Overestimate is a node whose FValue exceeds its cost to T() by Excess.
+type Overestimate struct {
+	Node   int
+	FValue float64
+	Cost   float64
+	Excess float64
+}
+
+// Inconsistency is an edge from U to V such that FValue(U) exceeds its cost plus FValue(V) by Excess.
+type Inconsistency struct {
+	Edge   Edge
+	Cost   float64
+	FValue float64 // FValue(U)
+	Next   float64 // FValue(V)
+	Excess float64
+}
+
+// Admissible reports whether no node is overestimated.
+func (r *HeuristicReport) Admissible() bool {
+	return len(r.Overestimates) == 0
+}
+
+// Consistent reports whether no edge is inconsistent. A consistent heuristic is admissible if FValue(T()) is 0.
+func (r *HeuristicReport) Consistent() bool {
+	return len(r.Inconsistencies) == 0
+}
+
+// heuristicTolerance is the excess of a heuristic value attributed to rounding, relative to the costs involved.
+const heuristicTolerance = 1e-9
+
+// CheckHeuristic compares the FValue of every node reachable from g.S() with its exact cost to g.T(), computed with
+// a Dijkstra search from T() along the reversed edges, and checks the consistency of every edge between those nodes.
+// It holds the whole reachable graph in memory, so it is meant for small and medium graphs.
+func CheckHeuristic(g Graph) *HeuristicReport {
+	conns := make(map[int]map[int][]float64)
+	incoming := make(map[int]map[int][]float64)
+	queue := []int{g.S()}
+	conns[g.S()] = nil
+	for len(queue) > 0 {
+		u := queue[0]
+		queue = queue[1:]
+		conns[u] = g.Connections(u)
+		for _, v := range sortedKeys(conns[u]) {
+			if incoming[v] == nil {
+				incoming[v] = make(map[int][]float64)
+			}
+			incoming[v][u] = conns[u][v]
+			if _, ok := conns[v]; !ok {
+				conns[v] = nil
+				queue = append(queue, v)
+			}
+		}
+	}
+
+	nodes := make([]int, 0, len(conns))
+	for n := range conns {
+		nodes = append(nodes, n)
+	}
+	sort.Ints(nodes)
+	report := &HeuristicReport{Nodes: len(nodes)}
+	cost := costsTo(g.T(), func(v int) map[int][]float64 {
+		return incoming[v]
+	})
+	for _, n := range nodes {
+		h := g.FValue(n)
+		if c, ok := cost[n]; ok && exceeds(h, c) {
+			report.Overestimates = append(report.Overestimates, Overestimate{Node: n, FValue: h, Cost: c, Excess: h - c})
+		}
+		for _, v := range sortedKeys(conns[n]) {
+			next := g.FValue(v)
+			for i, c := range conns[n][v] {
+				if exceeds(h, c+next) {
+					report.Inconsistencies = append(report.Inconsistencies, Inconsistency{
+						Edge: Edge{U: n, V: v, I: i}, Cost: c, FValue: h, Next: next, Excess: h - c - next,
+					})
+				}
+			}
+		}
+	}
+	return report
+}
+
+func exceeds(h, bound float64) bool {
+	return h-bound > heuristicTolerance*math.Max(1, math.Abs(bound))
+}
+
+// costsTo returns the cost to t from every node reaching it, following the edges given by incoming, as in
+// ReverseGraph.Incoming, backwards from t.
+func costsTo(t int, incoming func(v int) map[int][]float64) map[int]float64 {
+	cost := map[int]float64{t: 0}
+	done := make(map[int]bool)
+	pq := &costQueue{{n: t}}
+	for pq.Len() > 0 {
+		v := heap.Pop(pq).(nodeCost).n
+		if done[v] {
+			continue
+		}
+		done[v] = true
+		for u, costs := range incoming(v) {
+			for _, c := range costs {
+				c += cost[v]
+				if old, ok := cost[u]; !ok || c < old {
+					cost[u] = c
+					heap.Push(pq, nodeCost{u, c})
+				}
+			}
+		}
+	}
+	return cost
+}
+
+type nodeCost struct {
+	n    int
+	cost float64
+}
+
+// costQueue is a priority queue of nodes by cost.
+type costQueue []nodeCost
+
+func (q costQueue) Len() int            { return len(q) }
+func (q costQueue) Less(i, j int) bool  { return q[i].cost < q[j].cost }
+func (q costQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *costQueue) Push(x interface{}) { *q = append(*q, x.(nodeCost)) }
+func (q *costQueue) Pop() interface{} {
+	old := *q
+	nc := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return nc
+}
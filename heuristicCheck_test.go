@@ -0,0 +1,31 @@
+package kstar
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckHeuristic(t *testing.T) {
+	g := newDiamondGraph()
+	// node 4 does not reach T, any heuristic value is fine
+	g.graph[0][4] = []float64{1}
+	g.fValues = map[int]float64{0: 3, 1: 2, 2: 1, 4: 100}
+	report := CheckHeuristic(g)
+	if report.Nodes != 5 || !report.Admissible() || !report.Consistent() {
+		t.Errorf("Expected the exact heuristic to pass the checks, but found %+v.", report)
+	}
+
+	g.fValues[1], g.fValues[2] = 0.5, 2.5
+	report = CheckHeuristic(g)
+	expectedOverestimates := []Overestimate{{Node: 2, FValue: 2.5, Cost: 1, Excess: 1.5}}
+	if !reflect.DeepEqual(report.Overestimates, expectedOverestimates) {
+		t.Errorf("Expected the overestimates %+v, but found %+v.", expectedOverestimates, report.Overestimates)
+	}
+	expectedInconsistencies := []Inconsistency{
+		{Edge: Edge{0, 1, 0}, Cost: 1, FValue: 3, Next: 0.5, Excess: 1.5},
+		{Edge: Edge{2, 3, 0}, Cost: 1, FValue: 2.5, Next: 0, Excess: 1.5},
+	}
+	if !reflect.DeepEqual(report.Inconsistencies, expectedInconsistencies) {
+		t.Errorf("Expected the inconsistencies %+v, but found %+v.", expectedInconsistencies, report.Inconsistencies)
+	}
+}
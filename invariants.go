@@ -0,0 +1,180 @@
+package kstar
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// InvariantError reports the violations of the invariants of K* found by the checks of WithInvariantChecks, with
+// which the query panics.
+type InvariantError struct {
+	Violations []string
+}
+
+func (e *InvariantError) Error() string {
+	return fmt.Sprintf("kstar: %d invariant violations:\n\t%s", len(e.Violations), strings.Join(e.Violations, "\n\t"))
+}
+
+// invariants collects violations.
+type invariants []string
+
+func (inv *invariants) failf(format string, args ...interface{}) {
+	*inv = append(*inv, fmt.Sprintf(format, args...))
+}
+
+// panicIfViolated panics with an *InvariantError if any violation was found.
+func (inv invariants) panicIfViolated() {
+	if len(inv) > 0 {
+		panic(&InvariantError{Violations: inv})
+	}
+}
+
+// checkPathGraph checks the heaps of the path graph against the search tree of A*: the order and the position index
+// of every heap, the d values of the H_in heaps, and that the H_T heap of every tree node holds the roots of the H_in
+// heaps of the nodes on its tree path from S(), sharing the ones of the H_T heap of its parent.
+func (pg *pathGraph) checkPathGraph(as *astar) {
+	var inv invariants
+	s, t := as.g.S(), as.g.T()
+
+	for _, v := range sortedHeapKeys(pg.hin) {
+		hin := pg.hin[v]
+		name := fmt.Sprintf("H_in(%d)", v)
+		inv.checkHeap(name, hin)
+		for pos, n := range hin.pq {
+			hn, ok := n.(hinNode)
+			if !ok {
+				inv.failf("%s: node %d is a %T", name, pos, n)
+				continue
+			}
+			e := Edge{hn.u, hn.v, hn.i}
+			switch {
+			case hn.v != v:
+				inv.failf("%s: node %d holds %v, arriving at another node", name, pos, e)
+			case hn.vHin != hin:
+				inv.failf("%s: node %d, %v, points to another H_in heap", name, pos, e)
+			case e == as.searchTreeParents[v] && v != s:
+				inv.failf("%s: node %d, %v, is a tree edge", name, pos, e)
+			case hn.d != as.dValue(e):
+				inv.failf("%s: node %d, %v, has d %g, expected %g", name, pos, e, hn.d, as.dValue(e))
+			}
+			// the root has a single child at 1, the children of any other node p are 2p and 2p+1
+			if parent := pos / 2; pos > 0 && hin.pq[parent].D() > hn.d {
+				inv.failf("%s: node %d, %v, with d %g is below d %g", name, pos, e, hn.d, hin.pq[parent].D())
+			}
+		}
+	}
+
+	for _, n := range sortedHeapKeys(pg.ht) {
+		ht := pg.ht[n]
+		name := fmt.Sprintf("H_T(%d)", n)
+		inv.checkHeap(name, ht)
+		for pos := range ht.pq {
+			if pos > 0 && ht.pq[(pos-1)/2].D() > ht.pq[pos].D() {
+				inv.failf("%s: node %d with d %g is below d %g", name, pos, ht.pq[pos].D(), ht.pq[(pos-1)/2].D())
+			}
+		}
+
+		expected := make(map[Edge]bool)
+		if n != s {
+			parent := as.searchTreeParents[n]
+			parentHt, ok := pg.ht[parent.U]
+			switch {
+			case parent == (Edge{}):
+				inv.failf("%s: %d has no parent in the search tree", name, n)
+			case !ok:
+				inv.failf("%s: the parent %d of %d has no H_T heap", name, parent.U, n)
+			case as.searchTreeChildren[parent.U][n] == nil:
+				inv.failf("%s: %d is not among the children of its parent %d", name, n, parent.U)
+			}
+			if ok {
+				for _, pn := range parentHt.pq {
+					expected[edgeOf(pn)] = true
+				}
+			}
+		}
+		if hin := pg.hin[n]; hin != nil && !hin.Empty() {
+			expected[edgeOf(hin.Top().(pathGraphNode))] = true
+		}
+		for pos, pn := range ht.pq {
+			htn, ok := pn.(htNode)
+			if !ok {
+				inv.failf("%s: node %d is a %T", name, pos, pn)
+				continue
+			}
+			e := edgeOf(htn)
+			if htn.ht != ht {
+				inv.failf("%s: node %d, %v, points to another H_T heap", name, pos, e)
+			}
+			if root := pg.hin[e.V]; root == nil || root.Empty() || !root.Top().(hinNode).equals(*htn.hinNode) {
+				inv.failf("%s: node %d, %v, is not the root of H_in(%d)", name, pos, e, e.V)
+			} else if htn.hinNode.vHin != root {
+				inv.failf("%s: node %d, %v, points to a former H_in(%d) heap", name, pos, e, e.V)
+			}
+			if !expected[e] {
+				inv.failf("%s: node %d, %v, is neither in the H_T heap of the parent nor the root of H_in(%d)", name, pos, e, n)
+			}
+			delete(expected, e)
+		}
+		for e := range expected {
+			inv.failf("%s: %v is missing", name, e)
+		}
+	}
+
+	if as.closed(t) && pg.r.tHt != pg.ht[t] {
+		inv.failf("R: does not point to H_T(%d)", t)
+	}
+	inv.panicIfViolated()
+}
+
+// checkHeap checks that the position index of h matches its nodes.
+func (inv *invariants) checkHeap(name string, h *pathGraphHeap) {
+	for pos, n := range h.pq {
+		u, v, i := n.EdgeKeys()
+		if !h.nodes.exists(u, v, i) || h.nodes[u][v][i] != pos {
+			inv.failf("%s: node %d, %v, is indexed at %d", name, pos, Edge{u, v, i}, h.nodes[u][v][i])
+		}
+	}
+	for u, vs := range h.nodes {
+		for v, is := range vs {
+			for i, pos := range is {
+				if pos == undefinedPos {
+					continue
+				}
+				if pos < 0 || pos >= h.Len() || edgeOf(h.pq[pos]) != (Edge{u, v, i}) {
+					inv.failf("%s: %v is indexed at %d, where it is not", name, Edge{u, v, i}, pos)
+				}
+			}
+		}
+	}
+}
+
+// checkPath checks that path, found by Dijkstra with key, costs key plus the cost of the shortest path.
+func (ks *kstar) checkPath(path []Edge, key float64) {
+	cost := 0.0
+	for _, e := range path {
+		cost += ks.as.edgeCost(e)
+	}
+	expected := key + ks.as.minPathCost()
+	if math.Abs(cost-expected) > heuristicTolerance*math.Max(1, math.Abs(expected)) {
+		var inv invariants
+		inv.failf("path %d: %v costs %g, but its key %g plus the cost %g of the shortest path is %g",
+			ks.emitted+1, path, cost, key, ks.as.minPathCost(), expected)
+		inv.panicIfViolated()
+	}
+}
+
+func edgeOf(n pathGraphNode) Edge {
+	u, v, i := n.EdgeKeys()
+	return Edge{u, v, i}
+}
+
+func sortedHeapKeys(heaps map[int]*pathGraphHeap) []int {
+	keys := make([]int, 0, len(heaps))
+	for k := range heaps {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
@@ -0,0 +1,65 @@
+package kstar
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestInvariantChecks(t *testing.T) {
+	rnd := rand.New(rand.NewSource(11))
+	for test := 0; test < 300; test++ {
+		n := 2 + rnd.Intn(20)
+		g := randomGraph(rnd, n, rnd.Intn(3*n))
+		for u := 0; u < n && test%2 == 1; u++ {
+			if u != g.t {
+				g.fValues[u] = float64(rnd.Intn(6))
+			}
+		}
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Errorf("Graph %d %v failed! %v", test, g, r)
+				}
+			}()
+			RunWithOptions(g, 30, WithInvariantChecks())
+			// resuming A* after every expansion updates the path graph the most times
+			RunWithOptions(g, 30, WithInvariantChecks(), WithResumptionPolicy(FixedBatchPolicy{Expansions: 1}))
+			s := NewSession(g, WithInvariantChecks())
+			for target := 0; target < n; target++ {
+				s.Paths(context.Background(), target, 5)
+			}
+		}()
+	}
+}
+
+func TestInvariantViolations(t *testing.T) {
+	var g Graph = newDiamondGraph()
+	ks := newKstar(&g, newPathGraph())
+	ks.emit = func(foundPath) {}
+	ks.run(1)
+
+	// corrupt the position index of H_T(T) and the d value of a sidetrack
+	ht := ks.pg.ht[3]
+	u, v, i := ht.pq[0].EdgeKeys()
+	ht.nodes[u][v][i] = 5
+	hin := ks.pg.hin[3]
+	hn := hin.pq[0].(hinNode)
+	hn.d++
+	hin.pq[0] = hn
+
+	defer func() {
+		ie, ok := recover().(*InvariantError)
+		if !ok {
+			t.Fatalf("Expected an *InvariantError panic, but found %v.", ie)
+		}
+		report := ie.Error()
+		for _, want := range []string{"H_T(3): node 0, {2 3 0}, is indexed at 5", "H_in(3): node 0, {2 3 0}, has d 1, expected 0"} {
+			if !strings.Contains(report, want) {
+				t.Errorf("Expected %q in the report, but found\n%s", want, report)
+			}
+		}
+	}()
+	ks.pg.checkPathGraph(ks.as)
+}
@@ -1,65 +1,292 @@
 package kstar
 
+import (
+	"context"
+	"io"
+	"math"
+	"time"
+)
+
 type kstar struct {
+	g           Graph
 	pg          *pathGraph
 	as          *astar
 	d           *dijkstra
-	paths       [][]Edge
+	emit        func(p foundPath) // hands the paths found by search to the query
+	emitted     int               // paths handed to emit by the current query
 	asExhausted bool
+	lastKey     float64 // key of the last path graph node popped by Dijkstra
+	stats       Stats
+	obs         Observer // nil if no observer is registered
+	ctx         context.Context
+	err         error  // why the query was interrupted
+	pending     []Edge // edges found by A* since the last rebuild of the path graph
+	stale       bool   // whether A* ran since the last rebuild of the path graph
+
+	pruneDead   bool
+	explain     bool
+	dump        io.Writer // where the path graph is written when the query ends, nil if it is not
+	dumpDepth   int
+	checks      bool // whether invariants are checked, see WithInvariantChecks
+	loopless    bool
+	maxExplored int     // paths to examine at most when loopless, 0 for no limit
+	costBound   float64 // cost of the most expensive path to return, +Inf if unbounded
 }
 
 func newKstar(g *Graph, pg *pathGraph) kstar {
 	return kstar{
-		pg:    pg,
-		as:    newAstar(*g),
-		d:     newDijkstra(&pg.r),
-		paths: make([][]Edge, 0),
+		g:         *g,
+		pg:        pg,
+		as:        newAstar(*g),
+		d:         newDijkstra(&pg.r),
+		ctx:       context.Background(),
+		costBound: math.Inf(1),
 	}
 }
 
 // Run returns the k shortest paths given a Graph implementation and k.
 func Run(g Graph, k int) (paths [][]Edge) {
+	paths, _ = RunWithOptions(g, k)
+	return paths
+}
+
+// RunWithOptions returns the k shortest paths given a Graph implementation and k, configured by opts,
+// along with statistics about the search.
+func RunWithOptions(g Graph, k int, opts ...Option) (paths [][]Edge, stats Stats) {
+	paths, stats, _ = RunContext(context.Background(), g, k, opts...)
+	return paths, stats
+}
+
+// RunContext is RunWithOptions interrupted when ctx is done, in which case it returns the paths found so far and
+// ctx.Err().
+func RunContext(ctx context.Context, g Graph, k int, opts ...Option) (paths [][]Edge, stats Stats, err error) {
+	paths = make([][]Edge, 0)
+	stats, err = query(ctx, g, k, opts, nil, func(p foundPath) {
+		paths = append(paths, p.path)
+	})
+	return paths, stats, err
+}
+
+// foundPath is a path found by search: its sidetracks, its cost, and its edges, nil if the query keeps the search
+// tree in a history and nothing else needs them.
+type foundPath struct {
+	seq  *seqNode
+	cost float64
+	path []Edge
+}
+
+// query runs K* on g for k paths, configured by opts, handing them to emit. history records the search tree when the
+// paths are built from it later, nil if they are built when found.
+func query(ctx context.Context, g Graph, k int, opts []Option, history *treeHistory, emit func(p foundPath)) (Stats, error) {
+	start := time.Now()
 	pg := newPathGraph()
 	ks := newKstar(&g, pg)
-	paths = make([][]Edge, 0)
+	ks.ctx = ctx
+	ks.as.ctx = ctx
+	ks.as.history = history
+	ks.emit = emit
+	newOptions(opts...).apply(&ks)
+	ks.run(k)
+	ks.dumpPathGraph()
+	ks.collectStats()
+	ks.stats.TotalTime = time.Since(start)
+	return ks.stats, ks.err
+}
+
+func (ks *kstar) run(k int) {
+	if ks.pruneDead && !ks.findDeadEnds() {
+		// S() cannot reach T(), or dead ends cannot be found
+		return
+	}
 	tReached := ks.startAstar()
-	if tReached {
-		for len(paths) < k {
-			sigmaPath, empty := ks.d.step()
-			edgeSeq := buildSeq(sigmaPath)
-			path := buildPath(edgeSeq, ks.as.searchTreeParents, g.S(), g.T())
-			paths = append(paths, path)
-			if empty {
-				if ks.asExhausted {
-					break
-				}
-				ks.resumeAstar()
-				end := ks.d.resume()
-				if end {
-					break
-				}
+	if !tReached {
+		return
+	}
+	ks.search(k)
+}
+
+// search pops paths from Dijkstra until k are found, resuming A* when needed. T() must have been reached.
+func (ks *kstar) search(k int) {
+	popped := newSeqTrie()
+	explored := 0
+	for ks.emitted < k {
+		if ks.err = ks.ctx.Err(); ks.err != nil {
+			return
+		}
+		if !ks.asExhausted && !ks.canPop() {
+			if !ks.resumeAstar() {
+				return
 			}
+			continue
+		}
+		if ks.d.Empty() {
+			break
+		}
+
+		start := time.Now()
+		sigmaPath := ks.d.step()
+		ks.lastKey = sigmaPath[len(sigmaPath)-1].cost
+		ks.stats.DijkstraTime += time.Since(start)
+		cost := ks.lastKey + ks.as.minPathCost()
+		if cost > ks.costBound {
+			// paths come out in cost order, the remaining ones are all above the bound
+			break
+		}
+
+		start = time.Now()
+		edgeSeq := buildSeq(sigmaPath)
+		node, added := popped.add(edgeSeq)
+		if !added {
+			// found again after a restart of Dijkstra
+			ks.stats.PathTime += time.Since(start)
+			continue
+		}
+		var path []Edge
+		if ks.as.history == nil || ks.loopless || ks.explain || ks.obs != nil || ks.checks {
+			path = buildPath(edgeSeq, ks.parent, ks.g.S(), ks.g.T())
+		}
+		if ks.checks {
+			ks.checkPath(path, ks.lastKey)
+		}
+		ks.stats.PathTime += time.Since(start)
+		explored++
+		if !ks.loopless || !hasLoops(path) {
+			ks.emitted++
+			ks.emit(foundPath{seq: node, cost: cost, path: path})
+			if ks.as.weight > 1 {
+				ks.stats.LowerBounds = append(ks.stats.LowerBounds, ks.lowerBound(cost))
+			}
+			if ks.explain {
+				ks.stats.Explanations = append(ks.stats.Explanations, ks.as.explain(edgeSeq, path))
+			}
+			if ks.obs != nil {
+				ks.obs.PathEmitted(ks.emitted, path)
+			}
+		}
+		if ks.loopless && explored == ks.maxExplored {
+			break
 		}
 	}
+}
 
-	return paths
+// canPop reports whether the next path of Dijkstra is known to be the cheapest remaining one: any path not yet in the
+// path graph goes through a node open in A*, so it costs at least the f value of the top of A*.
+func (ks *kstar) canPop() bool {
+	if ks.d.Empty() {
+		return false
+	}
+	return ks.d.Top().(*dijkstraNode).cost+ks.as.minPathCost() <= ks.as.fScore(ks.as.Top().(int))
+}
+
+// lowerBound returns a lower bound of the cost of the exact next shortest path when a path costing cost is returned by
+// a weighted search. Either that path is already in the path graph, and costs at least as much as the returned one,
+// or it goes through an open node n with g(n) at most its cost up to n, which is then at least fScore(n)/weight for
+// an admissible heuristic.
+func (ks *kstar) lowerBound(cost float64) float64 {
+	if ks.asExhausted || ks.as.Empty() {
+		return cost
+	}
+	return math.Min(cost, ks.as.fScore(ks.as.Top().(int))/ks.as.weight)
+}
+
+// findDeadEnds restricts A* to the nodes that can reach T(), returning false if S() is not among them or if g is not
+// a ReverseGraph, which fails the query with ErrNotReverseGraph.
+func (ks *kstar) findDeadEnds() bool {
+	rg, ok := asReverseGraph(ks.g)
+	if !ok {
+		ks.err = ErrNotReverseGraph
+		return false
+	}
+	start := time.Now()
+	ks.as.reaching = reachingNodes(rg)
+	ks.stats.AstarTime += time.Since(start)
+	return ks.as.reaching[ks.g.S()]
 }
 
 func (ks *kstar) startAstar() (tReached bool) {
+	start := time.Now()
 	newEdges, end := ks.as.run()
-	if !end {
-		ks.pg.updateHinNodes(newEdges, ks.as)
-		ks.pg.generateHts(ks.as)
-		tHt := ks.pg.ht[ks.as.g.T()]
-		ks.pg.r.tHt = tHt
+	ks.stats.AstarTime += time.Since(start)
+	ks.asExhausted = end
+	if ks.err = ks.ctx.Err(); ks.err != nil || end {
+		ks.keep(newEdges)
+		return false
 	}
-	return !end
+	ks.rebuild(newEdges)
+	return true
 }
 
-func (ks *kstar) resumeAstar() {
+// resumeAstar resumes A* and updates the path graph, returning false if the query was interrupted.
+func (ks *kstar) resumeAstar() bool {
+	ks.stats.Resumptions++
+	ks.as.c.dijkstraKey = ks.lastKey
+	if !ks.d.Empty() {
+		ks.as.c.dijkstraKey = ks.d.Top().(*dijkstraNode).cost
+	}
+	if ks.obs != nil {
+		ks.obs.AstarResumed(ks.stats.Resumptions)
+	}
+	start := time.Now()
 	newEdges, end := ks.as.run()
+	ks.stats.AstarTime += time.Since(start)
 	ks.asExhausted = end
-	ks.pg.updateHinNodes(newEdges, ks.as)
+	if ks.err = ks.ctx.Err(); ks.err != nil {
+		ks.keep(newEdges)
+		return false
+	}
+	ks.rebuild(newEdges)
+	return true
+}
+
+// keep holds the edges found by an A* run whose path graph update is skipped, for the next rebuild.
+func (ks *kstar) keep(newEdges []Edge) {
+	ks.pending = append(ks.pending, newEdges...)
+	ks.stale = true
+}
+
+// rebuild updates the path graph with the edges found by A* and restarts Dijkstra on it. The paths already returned
+// are found again and skipped by search.
+func (ks *kstar) rebuild(newEdges []Edge) {
+	start := time.Now()
+	rootChanged := ks.pg.updateHinNodes(append(ks.pending, newEdges...), ks.as)
+	ks.pg.updateHts(ks.as, rootChanged)
+	ks.as.changed = make(map[int]bool)
+	ks.pending, ks.stale = nil, false
+	ks.stats.PathGraphTime += time.Since(start)
+	if ks.checks {
+		ks.pg.checkPathGraph(ks.as)
+	}
+
+	ks.stats.DijkstraPops += ks.d.pops
+	ks.d = newDijkstra(&ks.pg.r)
+	ks.d.obs = ks.obs
+}
+
+// dumpPathGraph writes the path graph if WithPathGraphDump was set.
+func (ks *kstar) dumpPathGraph() {
+	if ks.dump == nil {
+		return
+	}
+	if err := ks.pg.writeDOT(ks.dump, ks.dumpDepth); err != nil && ks.err == nil {
+		ks.err = err
+	}
+}
+
+func (ks *kstar) collectStats() {
+	ks.stats.ExpandedNodes = ks.as.c.expandedNodes
+	ks.stats.ReopenedNodes = ks.as.reopenedNodes
+	ks.stats.PrunedEdges = ks.as.prunedEdges
+	ks.stats.DijkstraPops += ks.d.pops
+	ks.stats.HinHeaps, ks.stats.HinNodes = heapsSize(ks.pg.hin)
+	ks.stats.HtHeaps, ks.stats.HtNodes = heapsSize(ks.pg.ht)
+}
+
+func heapsSize(heaps map[int]*pathGraphHeap) (nHeaps, nNodes int) {
+	for _, h := range heaps {
+		nHeaps++
+		nNodes += h.Len()
+	}
+	return
 }
 
 // Transforms a dijkstra path into a sequence of sidetrack edges
@@ -87,8 +314,57 @@ func buildSeq(pgPath []*dijkstraNode) (seq []Edge) {
 
 }
 
-// Adds tree nodes to the sidetrack edges to complete the path
-func buildPath(seq []Edge, spTree map[int]Edge, s, t int) (path []Edge) {
+// seqNode is a sequence of sidetrack edges, from S() to T(): e followed by rest, nil if e is the last one.
+type seqNode struct {
+	e    Edge
+	rest *seqNode
+}
+
+// edges returns the sequence of n, nil for the empty one.
+func (n *seqNode) edges() (seq []Edge) {
+	for ; n != nil; n = n.rest {
+		seq = append(seq, n.e)
+	}
+	return seq
+}
+
+// seqTrie holds the sequences of sidetrack edges of the paths popped by Dijkstra, so that the ones found again after a
+// restart are skipped. Sequences share their ends towards T(): the one of a path graph node extends the one of the
+// node it was reached from by a cross edge, which was popped before it, so each path only adds a node.
+type seqTrie struct {
+	nodes map[seqNode]*seqNode
+	empty bool // whether the sequence of the shortest path, which has no sidetracks, was added
+}
+
+func newSeqTrie() *seqTrie {
+	return &seqTrie{nodes: make(map[seqNode]*seqNode)}
+}
+
+// add adds seq, from S() to T(), returning its node, nil for the empty sequence, and whether it was not there yet.
+func (t *seqTrie) add(seq []Edge) (node *seqNode, added bool) {
+	if len(seq) == 0 {
+		added, t.empty = !t.empty, true
+		return nil, added
+	}
+	for j := len(seq) - 1; j >= 0; j-- {
+		key := seqNode{e: seq[j], rest: node}
+		next, ok := t.nodes[key]
+		if !ok {
+			next = &seqNode{e: seq[j], rest: node}
+			t.nodes[key] = next
+		}
+		node, added = next, !ok
+	}
+	return node, added
+}
+
+// parent returns the edge arriving at n in the search tree.
+func (ks *kstar) parent(n int) Edge {
+	return ks.as.searchTreeParents[n]
+}
+
+// Adds tree nodes to the sidetrack edges to complete the path, parent giving the edges of the tree
+func buildPath(seq []Edge, parent func(n int) Edge, s, t int) (path []Edge) {
 	path = make([]Edge, 0)
 	current := t
 	for current != s || len(seq) != 0 {
@@ -98,8 +374,9 @@ func buildPath(seq []Edge, spTree map[int]Edge, s, t int) (path []Edge) {
 			seq = seq[:len(seq)-1]
 			current = e.U
 		} else {
-			path = append(path, spTree[current])
-			current = spTree[current].U
+			e := parent(current)
+			path = append(path, e)
+			current = e.U
 		}
 	}
 	return path
@@ -2,10 +2,14 @@ package kstar
 
 import (
 	"bufio"
-	"encoding/json"
+	"bytes"
+	"container/heap"
+	"context"
 	"fmt"
 	"log"
+	"math/rand"
 	"os"
+	"reflect"
 	"strconv"
 	"strings"
 	"testing"
@@ -19,38 +23,38 @@ const (
 	inExtension   = ".in"
 )
 
-// TestOutputKstar represents a kstar test output file structure
+// TestOutputKstar represents a kstar test output file, holding the Result of a test.
 type TestOutputKstar struct {
-	TestName string
-	Paths    []TestPath
+	Result
 }
 
 func (to TestOutputKstar) Name() string {
-	return to.TestName
+	return fmt.Sprintf("%s.%d", to.GraphID, to.K)
 }
 
 func (to TestOutputKstar) Marshall() ([]byte, error) {
-	return json.MarshalIndent(to, "", "    ")
+	var buf bytes.Buffer
+	err := EncodeResult(&buf, &to.Result)
+	return buf.Bytes(), err
 }
 
-func (to *TestOutputKstar) Unmarshal(bytes []byte) error {
-	return json.Unmarshal(bytes, to)
+func (to *TestOutputKstar) Unmarshal(data []byte) error {
+	res, err := DecodeResult(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	to.Result = *res
+	return nil
 }
 
 func (to *TestOutputKstar) New(args ...interface{}) {
-	to.TestName = args[0].(string)
-	to.Paths = args[1].([]TestPath)
+	to.Result = *args[0].(*Result)
 }
 
 func (to *TestOutputKstar) TestFolderPath() string {
 	return kstarTestPath
 }
 
-type TestPath struct {
-	Edges []Edge
-	Cost  float64
-}
-
 func getPathCost(path []Edge, g *testutils.TestGraph) float64 {
 	cost := 0.0
 	for _, edge := range path {
@@ -68,25 +72,20 @@ func TestAllKstarInstances(t *testing.T) {
 	tgs := generateTests()
 	for _, tg := range tgs {
 		paths := Run(tg.tg, tg.k)
-		tPaths := make([]TestPath, 0)
-		for _, path := range paths {
-			tPaths = append(tPaths, TestPath{
-				Edges: path,
-				Cost:  getPathCost(path, &tg.tg),
-			})
-		}
+		graphID := strings.TrimSuffix(tg.tg.TestName, fmt.Sprintf(".%d", tg.k))
+		res := NewResult(graphID, tg.tg, tg.k, paths, nil)
 		to := new(TestOutputKstar)
-		found := testutils.ReadTestOutput(to, tg.tg.TestName, tg.tg.TestName, tPaths)
+		found := testutils.ReadTestOutput(to, tg.tg.TestName, res)
 		if found {
 			// test
-			expectedPaths := to.Paths
-			for i, expectedPath := range expectedPaths {
+			if len(paths) != len(to.Paths) {
+				t.Errorf("Test %s failed! Found %d paths, but expected %d.", tg.tg.TestName, len(paths), len(to.Paths))
+				continue
+			}
+			for i, expectedPath := range to.KstarPaths() {
 				path := paths[i]
-				for j, expectedEdge := range expectedPath.Edges {
-					edge := path[j]
-					if !expectedEdge.equals(&edge) {
-						t.Errorf("Test %s failed! Path %d was\n%s\n, but expected\n%s", tg.tg.TestName, i, printPath(path), printPath(expectedPath.Edges))
-					}
+				if len(path) != len(expectedPath) || !reflect.DeepEqual(res.Paths[i], to.Paths[i]) {
+					t.Errorf("Test %s failed! Path %d was\n%s\n, but expected\n%s", tg.tg.TestName, i, printPath(path), printPath(expectedPath))
 				}
 			}
 		}
@@ -138,3 +137,295 @@ func (e1 *Edge) equals(e2 *Edge) bool {
 func (e *Edge) String() string {
 	return fmt.Sprintf("{U:%d, V:%d, I:%d}", e.U, e.V, e.I)
 }
+
+func TestRunWithOptionsStats(t *testing.T) {
+	paths, stats := RunWithOptions(newDiamondGraph(), 10)
+	if len(paths) != 4 {
+		t.Fatalf("Expected 4 paths, but found %d.", len(paths))
+	}
+	if stats.ExpandedNodes != 4 {
+		t.Errorf("Expected 4 expanded nodes, but found %d.", stats.ExpandedNodes)
+	}
+	if stats.DijkstraPops < len(paths) {
+		t.Errorf("Expected at least %d Dijkstra pops, but found %d.", len(paths), stats.DijkstraPops)
+	}
+	if stats.HinHeaps != 2 || stats.HinNodes != 3 {
+		t.Errorf("Expected 2 H_in heaps with 3 nodes, but found %d heaps with %d nodes.", stats.HinHeaps, stats.HinNodes)
+	}
+	if stats.TotalTime <= 0 {
+		t.Error("Total time not measured.")
+	}
+}
+
+type recordingObserver struct {
+	NopObserver
+	expanded, sidetracks, resumptions, pops int
+	ranks                                   []int
+	seen                                    map[Edge]bool
+	repeated                                []Edge
+}
+
+func (o *recordingObserver) NodeExpanded(n int, g float64, reopened bool) { o.expanded++ }
+
+func (o *recordingObserver) SidetrackAdded(e Edge, d float64) {
+	o.sidetracks++
+	if o.seen == nil {
+		o.seen = make(map[Edge]bool)
+	}
+	if o.seen[e] {
+		o.repeated = append(o.repeated, e)
+	}
+	o.seen[e] = true
+}
+
+func (o *recordingObserver) AstarResumed(resumption int) { o.resumptions = resumption }
+
+func (o *recordingObserver) PathGraphNodePopped(e Edge, cost float64) { o.pops++ }
+
+func (o *recordingObserver) PathEmitted(rank int, path []Edge) { o.ranks = append(o.ranks, rank) }
+
+func TestObserver(t *testing.T) {
+	for _, tg := range generateTests() {
+		obs := new(recordingObserver)
+		paths, stats := RunWithOptions(tg.tg, tg.k, WithObserver(obs))
+		if obs.expanded != stats.ExpandedNodes || obs.resumptions != stats.Resumptions || obs.pops != stats.DijkstraPops {
+			t.Errorf("Test %s failed! Observed events %+v do not match stats %+v.", tg.tg.TestName, *obs, stats)
+		}
+		if obs.sidetracks < stats.HinNodes {
+			t.Errorf("Test %s failed! Observed %d sidetracks, but H_in heaps hold %d.", tg.tg.TestName, obs.sidetracks, stats.HinNodes)
+		}
+		if len(obs.ranks) != len(paths) || len(paths) > 0 && obs.ranks[len(paths)-1] != len(paths) {
+			t.Errorf("Test %s failed! Observed path ranks %v for %d paths.", tg.tg.TestName, obs.ranks, len(paths))
+		}
+
+		// every resumption updates the path graph, without reporting the sidetracks found before again
+		obs = new(recordingObserver)
+		RunWithOptions(tg.tg, tg.k, WithObserver(obs), WithResumptionPolicy(FixedBatchPolicy{Expansions: 1}))
+		if len(obs.repeated) > 0 {
+			t.Errorf("Test %s failed! Sidetracks %v were reported more than once.", tg.tg.TestName, obs.repeated)
+		}
+	}
+}
+
+func TestResumptionPolicies(t *testing.T) {
+	policies := []ResumptionPolicy{
+		FixedBatchPolicy{Expansions: 1},
+		CostHorizonPolicy{Delta: 0.5},
+		ExhaustivePolicy{},
+	}
+	for _, tg := range generateTests() {
+		expectedPaths := Run(tg.tg, tg.k)
+		for _, policy := range policies {
+			paths, _ := RunWithOptions(tg.tg, tg.k, WithResumptionPolicy(policy))
+			if len(paths) != len(expectedPaths) {
+				t.Errorf("Test %s failed with %T! Expected %d paths, but found %d.", tg.tg.TestName, policy, len(expectedPaths), len(paths))
+				continue
+			}
+			for i, path := range paths {
+				if cost, expectedCost := getPathCost(path, &tg.tg), getPathCost(expectedPaths[i], &tg.tg); cost != expectedCost {
+					t.Errorf("Test %s failed with %T! Path %d costs %f, but expected %f.", tg.tg.TestName, policy, i, cost, expectedCost)
+				}
+			}
+		}
+	}
+}
+
+type walk struct {
+	node int
+	cost float64
+}
+
+type walkHeap []walk
+
+func (h walkHeap) Len() int            { return len(h) }
+func (h walkHeap) Less(i, j int) bool  { return h[i].cost < h[j].cost }
+func (h walkHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *walkHeap) Push(x interface{}) { *h = append(*h, x.(walk)) }
+func (h *walkHeap) Pop() interface{} {
+	old := *h
+	w := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return w
+}
+
+// bruteForceCosts enumerates every walk from S() in cost order, returning the costs of the first k reaching T().
+// Walks entering nodes that cannot reach T() are dropped, and the enumeration is bounded, since the walks of a graph
+// with cycles grow exponentially in number.
+func bruteForceCosts(g Graph, k int) (costs []float64) {
+	nodes := []int{g.S()}
+	seen := map[int]bool{g.S(): true}
+	for i := 0; i < len(nodes); i++ {
+		for v := range g.Connections(nodes[i]) {
+			if !seen[v] {
+				seen[v] = true
+				nodes = append(nodes, v)
+			}
+		}
+	}
+	reaches := map[int]bool{g.T(): true}
+	for grown := true; grown; {
+		grown = false
+		for _, u := range nodes {
+			for v := range g.Connections(u) {
+				if reaches[v] && !reaches[u] {
+					reaches[u], grown = true, true
+				}
+			}
+		}
+	}
+
+	h := &walkHeap{{node: g.S()}}
+	for pops := 0; h.Len() > 0 && len(costs) < k && pops < 100000; pops++ {
+		w := heap.Pop(h).(walk)
+		if w.node == g.T() {
+			costs = append(costs, w.cost)
+		}
+		for v, edges := range g.Connections(w.node) {
+			if !reaches[v] {
+				continue
+			}
+			for _, cost := range edges {
+				heap.Push(h, walk{node: v, cost: w.cost + cost})
+			}
+		}
+	}
+	return costs
+}
+
+// randomGraph builds a graph of n nodes with random S() and T() and m edges between random nodes, costing 1 to 5.
+func randomGraph(rnd *rand.Rand, n, m int) mockGraph {
+	g := newMockGraph(rnd.Intn(n), rnd.Intn(n))
+	for u := 0; u < n; u++ {
+		g.graph[u] = make(map[int][]float64)
+	}
+	for e := 0; e < m; e++ {
+		u, v := rnd.Intn(n), rnd.Intn(n)
+		g.graph[u][v] = append(g.graph[u][v], float64(1+rnd.Intn(5)))
+	}
+	return g
+}
+
+// setMinCostHeuristic sets the heuristic value of every node but T() to the cost of its cheapest edge, which is
+// admissible but not necessarily consistent.
+func setMinCostHeuristic(g mockGraph) {
+	for u, conns := range g.graph {
+		if u == g.t {
+			continue
+		}
+		minCost := 0.0
+		for _, costs := range conns {
+			for _, cost := range costs {
+				if minCost == 0 || cost < minCost {
+					minCost = cost
+				}
+			}
+		}
+		g.fValues[u] = minCost
+	}
+}
+
+func pathCost(g Graph, path []Edge) (cost float64) {
+	for _, e := range path {
+		cost += g.Connections(e.U)[e.V][e.I]
+	}
+	return cost
+}
+
+// checkBruteForce reports an error naming the graph if paths are not the k shortest ones of g found by
+// bruteForceCosts.
+func checkBruteForce(t *testing.T, name string, g Graph, k int, paths [][]Edge) {
+	expectedCosts := bruteForceCosts(g, k)
+	if len(paths) != len(expectedCosts) {
+		t.Errorf("%s failed! Expected %d paths, but found %d.", name, len(expectedCosts), len(paths))
+		return
+	}
+	for i, path := range paths {
+		if cost := pathCost(g, path); cost != expectedCosts[i] {
+			t.Errorf("%s failed! Path %d costs %f, but expected %f.", name, i, cost, expectedCosts[i])
+		}
+	}
+}
+
+func TestAgainstBruteForce(t *testing.T) {
+	for _, tg := range generateTests() {
+		checkBruteForce(t, "Test "+tg.tg.TestName, tg.tg, tg.k, Run(tg.tg, tg.k))
+	}
+}
+
+func TestRandomGraphsAgainstBruteForce(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for test := 0; test < 200; test++ {
+		n := 2 + rnd.Intn(6)
+		g := randomGraph(rnd, n, rnd.Intn(3*n))
+		if test%2 == 1 {
+			setMinCostHeuristic(g)
+		}
+		checkBruteForce(t, fmt.Sprintf("Graph %d %v", test, g), g, 8, Run(g, 8))
+	}
+}
+
+func TestQueryOptions(t *testing.T) {
+	g := newDiamondGraph()
+	if paths, _ := RunWithOptions(g, 10, WithCostBound(5)); len(paths) != 3 {
+		t.Errorf("Expected 3 paths costing at most 5, but found %d.", len(paths))
+	}
+
+	forbidden := Edge{U: 0, V: 1, I: 0}
+	paths, _ := RunWithOptions(g, 10, WithForbiddenEdges(forbidden))
+	if len(paths) != 3 {
+		t.Errorf("Expected 3 paths without %v, but found %d.", forbidden, len(paths))
+	}
+	for _, path := range paths {
+		for _, e := range path {
+			if e == forbidden {
+				t.Errorf("Path %s uses the forbidden edge.", printPath(path))
+			}
+		}
+	}
+
+	cyclic := newMockGraph(0, 2)
+	cyclic.graph[0] = map[int][]float64{1: {1}}
+	cyclic.graph[1] = map[int][]float64{0: {1}, 2: {1}}
+	if paths, _ := RunWithOptions(cyclic, 3, WithLoopless(50)); len(paths) != 1 || len(paths[0]) != 2 {
+		t.Errorf("Expected the only loopless path, but found %v.", paths)
+	}
+}
+
+func TestRunContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	paths, _, err := RunContext(ctx, newDiamondGraph(), 10)
+	if err != context.Canceled || len(paths) != 0 {
+		t.Errorf("Expected no paths and a cancellation error, but found %d paths and %v.", len(paths), err)
+	}
+	if paths, _, err := RunContext(context.Background(), newDiamondGraph(), 10); err != nil || len(paths) != 4 {
+		t.Errorf("Expected 4 paths and no error, but found %d paths and %v.", len(paths), err)
+	}
+}
+
+func TestWeightedAgainstBruteForce(t *testing.T) {
+	rnd := rand.New(rand.NewSource(3))
+	const w = 2
+	for test := 0; test < 200; test++ {
+		n := 2 + rnd.Intn(6)
+		g := randomGraph(rnd, n, rnd.Intn(3*n))
+		setMinCostHeuristic(g)
+
+		expectedCosts := bruteForceCosts(g, 8)
+		paths, stats := RunWithOptions(g, 8, WithWeight(w))
+		if len(paths) != len(expectedCosts) || len(stats.LowerBounds) != len(paths) {
+			t.Errorf("Graph %d %v failed! Expected %d paths and bounds, but found %d and %d.", test, g, len(expectedCosts), len(paths), len(stats.LowerBounds))
+			continue
+		}
+		for i, path := range paths {
+			cost, lb := pathCost(g, path), stats.LowerBounds[i]
+			if cost > w*expectedCosts[i] || lb > expectedCosts[i] || cost > w*lb {
+				t.Errorf("Graph %d %v failed! Path %d costs %f with lower bound %f, but the exact one costs %f.", test, g, i, cost, lb, expectedCosts[i])
+			}
+		}
+	}
+
+	if _, stats := RunWithOptions(newDiamondGraph(), 4); stats.LowerBounds != nil {
+		t.Errorf("Expected no lower bounds without weight, but found %v.", stats.LowerBounds)
+	}
+}
@@ -0,0 +1,141 @@
+package landmarks
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Landmarks files start with magic and the format version, followed by the number of nodes and of landmarks, the
+// landmarks and the costs from and to every one of them, all little endian.
+const (
+	magic   = "KSLM"
+	version = 1
+)
+
+// maxLandmarks bounds the landmarks Read accepts, far above the few dozens worth their cost. Costs are read in chunks
+// of costChunk, so that a corrupt header makes Read fail at the end of the file rather than allocate what the header
+// claims.
+const (
+	maxLandmarks = 1024
+	costChunk    = 1 << 16
+)
+
+// Write writes l to w in the format read by Read.
+func (l *Landmarks) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.WriteString(magic); err != nil {
+		return err
+	}
+	header := []uint32{version, uint32(l.numNodes), uint32(len(l.nodes))}
+	for _, n := range l.nodes {
+		header = append(header, uint32(n))
+	}
+	if err := binary.Write(bw, binary.LittleEndian, header); err != nil {
+		return err
+	}
+	for _, costs := range append(append([][]float64(nil), l.from...), l.to...) {
+		if err := binary.Write(bw, binary.LittleEndian, costs); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// WriteFile writes l to the file at path, creating or truncating it.
+func (l *Landmarks) WriteFile(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := l.Write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Read reads landmarks written by Write.
+func Read(r io.Reader) (*Landmarks, error) {
+	br := bufio.NewReader(r)
+	head := make([]byte, len(magic))
+	if _, err := io.ReadFull(br, head); err != nil || string(head) != magic {
+		return nil, errors.New("not a landmarks file")
+	}
+	var header [3]uint32
+	if err := binary.Read(br, binary.LittleEndian, &header); err != nil {
+		return nil, truncated(err)
+	}
+	if header[0] != version {
+		return nil, fmt.Errorf("unsupported landmarks file version %d", header[0])
+	}
+	numNodes, numLandmarks := int(header[1]), int(header[2])
+	if numLandmarks > numNodes {
+		return nil, fmt.Errorf("%d landmarks for %d nodes", numLandmarks, numNodes)
+	}
+	if numLandmarks > maxLandmarks {
+		return nil, fmt.Errorf("%d landmarks, at most %d are supported", numLandmarks, maxLandmarks)
+	}
+
+	l := &Landmarks{numNodes: numNodes}
+	nodes := make([]uint32, numLandmarks)
+	if err := binary.Read(br, binary.LittleEndian, nodes); err != nil {
+		return nil, truncated(err)
+	}
+	for _, n := range nodes {
+		if int(n) >= numNodes {
+			return nil, fmt.Errorf("landmark %d out of range [0, %d)", n, numNodes)
+		}
+		l.nodes = append(l.nodes, int(n))
+	}
+	for _, costs := range []*[][]float64{&l.from, &l.to} {
+		for i := 0; i < numLandmarks; i++ {
+			c, err := readCosts(br, numNodes)
+			if err != nil {
+				return nil, truncated(err)
+			}
+			*costs = append(*costs, c)
+		}
+	}
+	return l, nil
+}
+
+// ReadFile reads the landmarks file at path.
+func ReadFile(path string) (*Landmarks, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	l, err := Read(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return l, nil
+}
+
+// readCosts reads n costs, allocating them as they are read.
+func readCosts(r io.Reader, n int) ([]float64, error) {
+	var costs []float64
+	chunk := make([]float64, costChunk)
+	for len(costs) < n {
+		if rest := n - len(costs); rest < len(chunk) {
+			chunk = chunk[:rest]
+		}
+		if err := binary.Read(r, binary.LittleEndian, chunk); err != nil {
+			return nil, err
+		}
+		costs = append(costs, chunk...)
+	}
+	return costs, nil
+}
+
+func truncated(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
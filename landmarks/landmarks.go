@@ -0,0 +1,290 @@
+// Package landmarks implements the ALT heuristic (A*, landmarks and triangle inequality). The costs from and to a few
+// landmark nodes are computed once, and the cost from u to v is bounded below by
+//
+//	max over the landmarks L of d(L, v) - d(L, u) and d(u, L) - d(v, L)
+//
+// The bound is admissible for any target, so that a single preprocessing serves every query on a graph.
+package landmarks
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/jcasado94/kstar"
+)
+
+// Strategy is the way Select picks landmarks.
+type Strategy int
+
+const (
+	// Random picks landmarks uniformly at random.
+	Random Strategy = iota
+	// Farthest picks every landmark as far as possible from the ones already picked.
+	Farthest
+	// Avoid picks every landmark in the region of the graph where the ones already picked give the worst bounds, as
+	// proposed by Goldberg and Werneck.
+	Avoid
+)
+
+// Landmarks holds the costs between a few landmark nodes and every node of a graph.
+type Landmarks struct {
+	nodes    []int
+	numNodes int
+	from     [][]float64 // from[i][n] is the cost from the ith landmark to n, +Inf if unreachable
+	to       [][]float64 // to[i][n] is the cost from n to the ith landmark, +Inf if unreachable
+}
+
+// Compute returns the given landmarks of g, running Dijkstra from and to every one of them.
+func Compute(g *kstar.AdjacencyGraph, nodes []int) (*Landmarks, error) {
+	nw := newNetwork(g)
+	l := &Landmarks{numNodes: g.NumNodes()}
+	for _, n := range nodes {
+		if n < 0 || n >= l.numNodes {
+			return nil, fmt.Errorf("landmark %d out of range [0, %d)", n, l.numNodes)
+		}
+		l.add(nw, n)
+	}
+	return l, nil
+}
+
+// Select picks n landmarks of g with strategy, drawing random numbers from rnd, and computes their costs. It picks
+// every node if g has no more than n, and fails if g has none.
+func Select(g *kstar.AdjacencyGraph, n int, strategy Strategy, rnd *rand.Rand) (*Landmarks, error) {
+	if g.NumNodes() == 0 {
+		return nil, errors.New("no landmarks to pick in a graph without nodes")
+	}
+	nw := newNetwork(g)
+	l := &Landmarks{numNodes: g.NumNodes()}
+	if n > l.numNodes {
+		n = l.numNodes
+	}
+	picked := make([]bool, l.numNodes)
+	pick := func(node int) {
+		picked[node] = true
+		l.add(nw, node)
+	}
+
+	switch strategy {
+	case Farthest:
+		// the first landmark is the farthest node from a random one
+		closest := nw.shortestPaths(rnd.Intn(l.numNodes), false).dist
+		for len(l.nodes) < n {
+			node := farthest(closest, picked)
+			if node == -1 {
+				node = randomNode(picked, rnd)
+			}
+			pick(node)
+			for v, d := range l.from[len(l.nodes)-1] {
+				closest[v] = math.Min(closest[v], d)
+			}
+		}
+	case Avoid:
+		for len(l.nodes) < n {
+			node := l.avoid(nw, rnd.Intn(l.numNodes), picked)
+			if node == -1 {
+				node = randomNode(picked, rnd)
+			}
+			pick(node)
+		}
+	default:
+		for len(l.nodes) < n {
+			pick(randomNode(picked, rnd))
+		}
+	}
+	return l, nil
+}
+
+// Nodes returns the landmarks.
+func (l *Landmarks) Nodes() []int {
+	return append([]int(nil), l.nodes...)
+}
+
+// NumNodes returns the number of nodes of the graph the landmarks were computed on.
+func (l *Landmarks) NumNodes() int {
+	return l.numNodes
+}
+
+// Estimate returns a lower bound of the cost from u to v, 0 if any of them is not in the graph.
+func (l *Landmarks) Estimate(u, v int) (h float64) {
+	if u < 0 || v < 0 || u >= l.numNodes || v >= l.numNodes {
+		return 0
+	}
+	for i := range l.nodes {
+		// bounds involving unreachable nodes say nothing
+		if fu, fv := l.from[i][u], l.from[i][v]; !math.IsInf(fu, 1) && !math.IsInf(fv, 1) {
+			h = math.Max(h, fv-fu)
+		}
+		if tu, tv := l.to[i][u], l.to[i][v]; !math.IsInf(tu, 1) && !math.IsInf(tv, 1) {
+			h = math.Max(h, tu-tv)
+		}
+	}
+	return h
+}
+
+// Graph returns a Graph behaving as g whose FValue is the estimate to g.T().
+func (l *Landmarks) Graph(g kstar.Graph) kstar.Graph {
+	return kstar.WrapGraph(landmarkGraph{Graph: g, l: l}, g)
+}
+
+type landmarkGraph struct {
+	kstar.Graph
+	l *Landmarks
+}
+
+func (g landmarkGraph) FValue(n int) float64 {
+	return g.l.Estimate(n, g.T())
+}
+
+func (l *Landmarks) add(nw *network, n int) {
+	l.nodes = append(l.nodes, n)
+	l.from = append(l.from, nw.shortestPaths(n, false).dist)
+	l.to = append(l.to, nw.shortestPaths(n, true).dist)
+}
+
+// avoid returns the next landmark of the Avoid strategy from the shortest path tree of root, -1 if every node of the
+// tree is well covered. Every node weighs the gap between its cost from root and the bound of the current
+// landmarks; the landmark is the leaf reached from the heaviest subtree without landmarks by always descending to
+// the heaviest child.
+func (l *Landmarks) avoid(nw *network, root int, picked []bool) int {
+	tree := nw.shortestPaths(root, false)
+	size := make([]float64, l.numNodes)
+	covered := make([]bool, l.numNodes)
+	children := make([][]int, l.numNodes)
+	for i := len(tree.order) - 1; i >= 0; i-- {
+		v := tree.order[i]
+		covered[v] = covered[v] || picked[v]
+		if covered[v] {
+			size[v] = 0
+		} else {
+			size[v] += tree.dist[v] - l.Estimate(root, v)
+		}
+		if p := tree.parent[v]; p != -1 {
+			children[p] = append(children[p], v)
+			covered[p] = covered[p] || covered[v]
+			size[p] += size[v]
+		}
+	}
+
+	best := -1
+	for _, v := range tree.order {
+		if size[v] > 0 && (best == -1 || size[v] > size[best]) {
+			best = v
+		}
+	}
+	for best != -1 {
+		next := -1
+		for _, c := range children[best] {
+			if size[c] > 0 && (next == -1 || size[c] > size[next]) {
+				next = c
+			}
+		}
+		if next == -1 {
+			break
+		}
+		best = next
+	}
+	return best
+}
+
+// farthest returns the node not yet picked with the highest finite cost, -1 if there is none.
+func farthest(cost []float64, picked []bool) int {
+	best := -1
+	for v, c := range cost {
+		if !picked[v] && !math.IsInf(c, 1) && (best == -1 || c > cost[best]) {
+			best = v
+		}
+	}
+	return best
+}
+
+func randomNode(picked []bool, rnd *rand.Rand) int {
+	for {
+		if n := rnd.Intn(len(picked)); !picked[n] {
+			return n
+		}
+	}
+}
+
+type arc struct {
+	v    int
+	cost float64
+}
+
+// network holds the cheapest edge between every pair of adjacent nodes of a graph, in both directions.
+type network struct {
+	out, in [][]arc
+}
+
+func newNetwork(g *kstar.AdjacencyGraph) *network {
+	nw := &network{out: make([][]arc, g.NumNodes()), in: make([][]arc, g.NumNodes())}
+	// Edges are sorted, parallel edges come one after the other
+	for _, e := range g.Edges() {
+		cost := g.EdgeCost(e.U, e.V, e.I)
+		if e.I > 0 {
+			last := len(nw.out[e.U]) - 1
+			if cost < nw.out[e.U][last].cost {
+				nw.out[e.U][last].cost = cost
+				nw.in[e.V][len(nw.in[e.V])-1].cost = cost
+			}
+			continue
+		}
+		nw.out[e.U] = append(nw.out[e.U], arc{e.V, cost})
+		nw.in[e.V] = append(nw.in[e.V], arc{e.U, cost})
+	}
+	return nw
+}
+
+// shortestPathTree holds the costs from or to a node, the parent of every node in the tree and the order the nodes
+// were settled in. Unreachable nodes cost +Inf and have no parent, -1, like the root.
+type shortestPathTree struct {
+	dist   []float64
+	parent []int
+	order  []int
+}
+
+// shortestPaths runs Dijkstra from src, following the edges backwards if reverse is set.
+func (nw *network) shortestPaths(src int, reverse bool) shortestPathTree {
+	arcs := nw.out
+	if reverse {
+		arcs = nw.in
+	}
+	tree := shortestPathTree{dist: make([]float64, len(arcs)), parent: make([]int, len(arcs))}
+	for v := range tree.dist {
+		tree.dist[v], tree.parent[v] = math.Inf(1), -1
+	}
+	tree.dist[src] = 0
+	done := make([]bool, len(arcs))
+	pq := &queue{{src, 0}}
+	for pq.Len() > 0 {
+		u := heap.Pop(pq).(arc).v
+		if done[u] {
+			continue
+		}
+		done[u] = true
+		tree.order = append(tree.order, u)
+		for _, a := range arcs[u] {
+			if d := tree.dist[u] + a.cost; d < tree.dist[a.v] {
+				tree.dist[a.v], tree.parent[a.v] = d, u
+				heap.Push(pq, arc{a.v, d})
+			}
+		}
+	}
+	return tree
+}
+
+// queue is a priority queue of nodes by the cost in their arc.
+type queue []arc
+
+func (q queue) Len() int            { return len(q) }
+func (q queue) Less(i, j int) bool  { return q[i].cost < q[j].cost }
+func (q queue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *queue) Push(x interface{}) { *q = append(*q, x.(arc)) }
+func (q *queue) Pop() interface{} {
+	old := *q
+	a := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return a
+}
@@ -0,0 +1,153 @@
+package landmarks
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/jcasado94/kstar"
+)
+
+// newGrid returns a w by h grid with edges of pseudo-random costs in both directions, going from one corner to the
+// opposite one.
+func newGrid(w, h int, rnd *rand.Rand) *kstar.AdjacencyGraph {
+	g := kstar.NewAdjacencyGraph(w*h, 0, w*h-1)
+	for n := 0; n < w*h; n++ {
+		if n%w+1 < w {
+			g.AddEdge(n, n+1, float64(1+rnd.Intn(5)))
+			g.AddEdge(n+1, n, float64(1+rnd.Intn(5)))
+		}
+		if n+w < w*h {
+			g.AddEdge(n, n+w, float64(1+rnd.Intn(5)))
+			g.AddEdge(n+w, n, float64(1+rnd.Intn(5)))
+		}
+	}
+	return g
+}
+
+func TestEstimateIsAdmissible(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	g := newGrid(7, 6, rnd)
+	// a one-way edge and a node nobody reaches
+	g.AddEdge(g.NumNodes(), 3, 1)
+	nw := newNetwork(g)
+
+	for _, strategy := range []Strategy{Random, Farthest, Avoid} {
+		l, err := Select(g, 4, strategy, rnd)
+		if err != nil {
+			t.Fatal(err)
+		}
+		nodes := l.Nodes()
+		if len(nodes) != 4 {
+			t.Fatalf("Strategy %d picked %v, expected 4 landmarks.", strategy, nodes)
+		}
+		for i, n := range nodes {
+			for _, m := range nodes[i+1:] {
+				if n == m {
+					t.Errorf("Strategy %d picked %d twice.", strategy, n)
+				}
+			}
+		}
+
+		for u := 0; u < g.NumNodes(); u++ {
+			dist := nw.shortestPaths(u, false).dist
+			for v, d := range dist {
+				if h := l.Estimate(u, v); h > d || math.IsInf(h, 1) {
+					t.Errorf("Strategy %d estimates %f from %d to %d, which costs %f.", strategy, h, u, v, d)
+				}
+			}
+		}
+		for v, d := range nw.shortestPaths(nodes[0], false).dist {
+			if h := l.Estimate(nodes[0], v); !math.IsInf(d, 1) && h != d {
+				t.Errorf("Strategy %d estimates %f from landmark %d to %d, expected the exact cost %f.", strategy, h, nodes[0], v, d)
+			}
+		}
+	}
+}
+
+func pathCosts(g *kstar.AdjacencyGraph, paths [][]kstar.Edge) (costs []float64) {
+	for _, path := range paths {
+		cost := 0.0
+		for _, e := range path {
+			cost += g.EdgeCost(e.U, e.V, e.I)
+		}
+		costs = append(costs, cost)
+	}
+	return costs
+}
+
+func TestSelectEmptyGraph(t *testing.T) {
+	g := kstar.NewAdjacencyGraph(0, 0, 0)
+	for _, strategy := range []Strategy{Random, Farthest, Avoid} {
+		if _, err := Select(g, 4, strategy, rand.New(rand.NewSource(1))); err == nil {
+			t.Errorf("Strategy %d: expected an error for a graph without nodes.", strategy)
+		}
+	}
+}
+
+func TestLandmarkGraph(t *testing.T) {
+	rnd := rand.New(rand.NewSource(2))
+	g := newGrid(20, 20, rnd)
+	l, err := Select(g, 8, Avoid, rnd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := l.Graph(g).(kstar.EdgeCoster); !ok {
+		t.Error("Expected the landmark graph of an EdgeCoster to be one.")
+	}
+	if _, ok := l.Graph(g).(kstar.ReverseGraph); !ok {
+		t.Error("Expected the landmark graph of a ReverseGraph to be one.")
+	}
+
+	for _, target := range []int{g.T(), 42, 210} {
+		rg := g.Reroute(g.S(), target)
+		paths, stats := kstar.RunWithOptions(rg, 10)
+		altPaths, altStats := kstar.RunWithOptions(l.Graph(rg), 10)
+		if !reflect.DeepEqual(pathCosts(g, paths), pathCosts(g, altPaths)) {
+			t.Errorf("Expected the costs %v to %d, but found %v.", pathCosts(g, paths), target, pathCosts(g, altPaths))
+		}
+		if altStats.ExpandedNodes >= stats.ExpandedNodes {
+			t.Errorf("Expected fewer than %d expansions to %d, but found %d.", stats.ExpandedNodes, target, altStats.ExpandedNodes)
+		}
+	}
+}
+
+func TestReadWrite(t *testing.T) {
+	rnd := rand.New(rand.NewSource(3))
+	g := newGrid(5, 5, rnd)
+	g.AddEdge(g.NumNodes(), 0, 1)
+	l, err := Compute(g, []int{0, 12, 24})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := l.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	read, err := Read(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(l, read) {
+		t.Errorf("Expected %+v, but read %+v.", l, read)
+	}
+
+	if _, err := Compute(g, []int{g.NumNodes()}); err == nil {
+		t.Error("Expected an error for a landmark out of the graph.")
+	}
+	for name, bad := range map[string][]byte{
+		"magic":     append([]byte("KSXX"), data[4:]...),
+		"version":   append(append([]byte(magic), 9, 0, 0, 0), data[8:]...),
+		"truncated": data[:len(data)-1],
+		// headers claiming more than the file holds
+		"landmarks": append([]byte(magic), 1, 0, 0, 0, 255, 255, 255, 255, 255, 255, 0, 0),
+		"nodes":     append([]byte(magic), 1, 0, 0, 0, 255, 255, 255, 255, 1, 0, 0, 0, 0, 0, 0, 0),
+	} {
+		if _, err := Read(bytes.NewReader(bad)); err == nil {
+			t.Errorf("Expected an error for the %s case.", name)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/jcasado94/kstar/batch"
+	"github.com/jcasado94/kstar/graphio"
+)
+
+// runBatch answers the queries of a query file on one graph and writes their results to standard output as JSON
+// lines, in the order of the queries, see package batch.
+//
+//	kstar batch -graph file -queries file [-workers N] [-timeout 10s]
+func runBatch(args []string) {
+	fs := flag.NewFlagSet("kstar batch", flag.ExitOnError)
+	graphPath := fs.String("graph", "", "graph file")
+	queriesPath := fs.String("queries", "-", "query file, - for standard input")
+	costAttr := fs.String("cost-attr", "", "attribute holding edge costs in dot and graphml files (default cost)")
+	workers := fs.Int("workers", 0, "queries run at the same time (default GOMAXPROCS)")
+	timeout := fs.Duration("timeout", 0, "longest time a single query may run (default no limit)")
+	fs.Parse(args)
+
+	if *graphPath == "" || *graphPath == "-" {
+		log.Fatal("the graph must be read from a file, set -graph")
+	}
+	g, err := loadGraph(*graphPath, inputFormat(*graphPath), graphio.Attributes{Cost: *costAttr})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var r io.Reader = os.Stdin
+	if *queriesPath != "-" {
+		file, err := os.Open(*queriesPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+		r = file
+	}
+	queries, err := batch.ReadQueries(r)
+	if err != nil {
+		log.Fatalf("%s: %v", *queriesPath, err)
+	}
+
+	bw := bufio.NewWriter(os.Stdout)
+	enc := json.NewEncoder(bw)
+	failed := 0
+	start := time.Now()
+	batch.Runner{Workers: *workers, Timeout: *timeout, GraphID: *graphPath}.Stream(context.Background(), g, queries, func(i int, res batch.Result) {
+		if res.Err != nil {
+			failed++
+		}
+		if err := enc.Encode(res); err != nil {
+			log.Fatal(err)
+		}
+	})
+	if err := bw.Flush(); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("%d queries, %d failed, in %v", len(queries), failed, time.Since(start))
+}
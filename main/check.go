@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/jcasado94/kstar"
+	"github.com/jcasado94/kstar/graphio"
+)
+
+// check reports the violations of admissibility and consistency of the heuristic of a query, see
+// kstar.CheckHeuristic.
+//
+//	kstar check [-graph file] [-s N -t N] [-landmarks file.lm] [-max 20]
+func check(args []string) {
+	fs := flag.NewFlagSet("kstar check", flag.ExitOnError)
+	graphPath := fs.String("graph", "-", "graph file, - for standard input")
+	costAttr := fs.String("cost-attr", "", "attribute holding edge costs in dot and graphml input (default cost)")
+	s := fs.Int("s", -1, "departure node, overrides the one in the file")
+	t := fs.Int("t", -1, "arrival node, overrides the one in the file")
+	lmPath := fs.String("landmarks", "", "landmarks file written by kstar landmarks, checked instead of the file heuristic")
+	max := fs.Int("max", 20, "violations of each kind to list at most, 0 for all")
+	fs.Parse(args)
+
+	g, err := loadGraph(*graphPath, inputFormat(*graphPath), graphio.Attributes{Cost: *costAttr})
+	if err != nil {
+		log.Fatal(err)
+	}
+	newS, newT := g.S(), g.T()
+	if *s >= 0 {
+		newS = *s
+	}
+	if *t >= 0 {
+		newT = *t
+	}
+	checked, err := withLandmarks(g.Reroute(newS, newT), *lmPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	report := kstar.CheckHeuristic(checked)
+	fmt.Printf("%d nodes reachable from %d, %d overestimated, %d inconsistent edges\n",
+		report.Nodes, newS, len(report.Overestimates), len(report.Inconsistencies))
+	for i, o := range report.Overestimates {
+		if *max > 0 && i == *max {
+			fmt.Printf("... %d more overestimated nodes\n", len(report.Overestimates)-i)
+			break
+		}
+		fmt.Printf("node %d: h %g > cost to %d %g (+%g)\n", o.Node, o.FValue, newT, o.Cost, o.Excess)
+	}
+	for i, inc := range report.Inconsistencies {
+		if *max > 0 && i == *max {
+			fmt.Printf("... %d more inconsistent edges\n", len(report.Inconsistencies)-i)
+			break
+		}
+		e := inc.Edge
+		fmt.Printf("edge %d -> %d #%d: h %g > cost %g + h %g (+%g)\n", e.U, e.V, e.I, inc.FValue, inc.Cost, inc.Next, inc.Excess)
+	}
+	if !report.Admissible() {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"time"
+
+	"github.com/jcasado94/kstar"
+	"github.com/jcasado94/kstar/graphio"
+	"github.com/jcasado94/kstar/landmarks"
+)
+
+var strategies = map[string]landmarks.Strategy{
+	"random":   landmarks.Random,
+	"farthest": landmarks.Farthest,
+	"avoid":    landmarks.Avoid,
+}
+
+// computeLandmarks selects landmarks of a graph and writes their costs to a file, for the -landmarks flag of queries.
+//
+//	kstar landmarks -graph file -o file.lm [-n 16] [-strategy random|farthest|avoid] [-seed N]
+func computeLandmarks(args []string) {
+	fs := flag.NewFlagSet("kstar landmarks", flag.ExitOnError)
+	graphPath := fs.String("graph", "-", "graph file, - for standard input")
+	costAttr := fs.String("cost-attr", "", "attribute holding edge costs in dot and graphml input (default cost)")
+	out := fs.String("o", "", "landmarks file to write")
+	n := fs.Int("n", 16, "number of landmarks")
+	strategy := fs.String("strategy", "avoid", "landmark selection: random, farthest or avoid")
+	seed := fs.Int64("seed", 1, "seed of the random choices of the selection")
+	fs.Parse(args)
+
+	st, ok := strategies[*strategy]
+	if !ok {
+		log.Fatalf("unknown strategy %q", *strategy)
+	}
+	if *out == "" {
+		log.Fatal("no landmarks file to write, set -o")
+	}
+	g, err := loadGraph(*graphPath, inputFormat(*graphPath), graphio.Attributes{Cost: *costAttr})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	start := time.Now()
+	l, err := landmarks.Select(g, *n, st, rand.New(rand.NewSource(*seed)))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := l.WriteFile(*out); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("wrote %d landmarks of %d nodes to %s in %v", len(l.Nodes()), g.NumNodes(), *out, time.Since(start))
+}
+
+// withLandmarks returns g with the heuristic of the landmarks file at path, g itself if path is empty.
+func withLandmarks(g *kstar.AdjacencyGraph, path string) (kstar.Graph, error) {
+	if path == "" {
+		return g, nil
+	}
+	l, err := landmarks.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if l.NumNodes() != g.NumNodes() {
+		return nil, fmt.Errorf("%s: computed for %d nodes, the graph has %d", path, l.NumNodes(), g.NumNodes())
+	}
+	return l.Graph(g), nil
+}
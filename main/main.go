@@ -1,4 +1,209 @@
+// Command kstar reads a graph and prints its k shortest paths from S to T, runs batches of queries or serves them over
+// HTTP.
+//
+// Usage:
+//
+//	kstar [-graph file.graph] [-input graph|dot|graphml] [-k 10] [-s N -t N] [-loopless]
+//	      [-landmarks file.lm | -exact] [-weight 1] [-prune] [-explain] [-format text|json|dot|graphml]
+//	      [-pathgraph file.dot [-pathgraph-depth N]]
+//	kstar landmarks -graph file -o file.lm [-n 16] [-strategy random|farthest|avoid]
+//	kstar check [-graph file] [-s N -t N] [-landmarks file.lm] [-max 20]
+//	kstar batch -graph file -queries file [-workers N] [-timeout 10s]
+//	kstar serve [-addr :8080] -graph name=file [-graph name=file ...] [-timeout 30s]
+//
+// The graph is read from standard input if no file is given, in the p/h/e text format unless -input is set or the
+// file extension is .dot, .gv or .graphml. S and T default to the ones in the file; the heuristic values in the file
+// are ignored if T is overridden. .graph files must match their p line and have strictly positive costs. The dot and
+// graphml formats write the whole graph with the paths highlighted. With a -weight above 1, paths may cost up to
+// weight times the exact ones, and the json format gives a lower bound of the exact cost of every rank. -explain lists
+// the sidetracks of every path, the edges it takes off the shortest path tree, under it in the text format and in the
+// json format.
+// -pathgraph writes the internal path graph of K* in the DOT language for debugging, see kstar.WithPathGraphDump.
+//
+// landmarks precomputes the ALT heuristic of a graph, as described in package landmarks, for the -landmarks flag,
+// which replaces the heuristic values of the graph file.
+//
+// check compares the heuristic of a query with the exact costs to T, listing the nodes it overestimates and the
+// edges where it is inconsistent, and exits with status 1 if it is not admissible.
+//
+// batch answers every query of a query file, as described in package batch, and writes one JSON line per query with
+// its line, its result and its error, in the order of the file.
+//
+// serve answers POST /v1/paths and GET /healthz as described in package server.
 package main
 
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jcasado94/kstar"
+	"github.com/jcasado94/kstar/graphio"
+)
+
 func main() {
+	log.SetFlags(0)
+	log.SetPrefix("kstar: ")
+
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "serve":
+			serve(os.Args[2:])
+			return
+		case "batch":
+			runBatch(os.Args[2:])
+			return
+		case "landmarks":
+			computeLandmarks(os.Args[2:])
+			return
+		case "check":
+			check(os.Args[2:])
+			return
+		}
+	}
+	query(os.Args[1:])
+}
+
+// query answers a single query and writes its paths to standard output.
+func query(args []string) {
+	fs := flag.NewFlagSet("kstar", flag.ExitOnError)
+	graphPath := fs.String("graph", "-", "graph file, - for standard input")
+	input := fs.String("input", "", "input format: graph, dot or graphml (default from the file extension)")
+	costAttr := fs.String("cost-attr", "", "attribute holding edge costs in dot and graphml input (default cost)")
+	k := fs.Int("k", 10, "number of paths")
+	s := fs.Int("s", -1, "departure node, overrides the one in the file")
+	t := fs.Int("t", -1, "arrival node, overrides the one in the file")
+	loopless := fs.Bool("loopless", false, "only return paths without loops")
+	maxK := fs.Int("max-k", 0, "paths to explore at most when -loopless is set (default 100*k)")
+	format := fs.String("format", "text", "output format: text, json, dot or graphml")
+	lmPath := fs.String("landmarks", "", "landmarks file written by kstar landmarks, used as the heuristic")
+	exact := fs.Bool("exact", false, "use the exact costs to T, computed with a backward Dijkstra, as the heuristic")
+	prune := fs.Bool("prune", false, "leave the nodes that cannot reach T out of the search")
+	explain := fs.Bool("explain", false, "describe every path by its sidetracks off the shortest path")
+	pgPath := fs.String("pathgraph", "", "file to write the path graph of K* to in the DOT language, for debugging")
+	pgDepth := fs.Int("pathgraph-depth", 0, "only write the path graph nodes within this many edges of its root (default all)")
+	weight := fs.Float64("weight", 1, "weight of the heuristic; above 1 paths may cost up to weight times the exact ones")
+	fs.Parse(args)
+
+	write, ok := formatters[*format]
+	if !ok {
+		log.Fatalf("unknown format %q", *format)
+	}
+	if *k < 1 {
+		log.Fatal("k must be positive")
+	}
+	if *exact && *lmPath != "" {
+		log.Fatal("-exact and -landmarks are mutually exclusive")
+	}
+
+	if *input == "" {
+		*input = inputFormat(*graphPath)
+	}
+	g, err := loadGraph(*graphPath, *input, graphio.Attributes{Cost: *costAttr})
+	if err != nil {
+		log.Fatal(err)
+	}
+	newS, newT := g.S(), g.T()
+	if *s >= 0 {
+		newS = *s
+	}
+	if *t >= 0 {
+		newT = *t
+	}
+	g = g.Reroute(newS, newT)
+
+	q := answer{g: g, k: *k}
+	if *graphPath != "-" {
+		q.graphID = *graphPath
+	}
+	var opts []kstar.Option
+	if *loopless {
+		if *maxK <= 0 {
+			*maxK = 100 * *k
+		}
+		opts = append(opts, kstar.WithLoopless(*maxK))
+	}
+	if *weight > 1 {
+		opts = append(opts, kstar.WithWeight(*weight))
+	}
+	if *prune {
+		opts = append(opts, kstar.WithDeadEndPruning())
+	}
+	if *explain {
+		opts = append(opts, kstar.WithExplanations())
+	}
+	searched, err := withLandmarks(g, *lmPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *exact {
+		searched = kstar.ExactHeuristic(g)
+	}
+	var dump *os.File
+	if *pgPath != "" {
+		if dump, err = os.Create(*pgPath); err != nil {
+			log.Fatal(err)
+		}
+		opts = append(opts, kstar.WithPathGraphDump(dump, *pgDepth))
+	}
+	q.paths, q.stats, err = kstar.RunContext(context.Background(), searched, *k, opts...)
+	if dump != nil {
+		// the path graph is only complete once the file is closed
+		if cerr := dump.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := write(os.Stdout, q); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// inputFormat guesses the format of the graph file at path from its extension.
+func inputFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".dot", ".gv":
+		return "dot"
+	case ".graphml":
+		return "graphml"
+	}
+	return "graph"
+}
+
+func loadGraph(path, format string, attrs graphio.Attributes) (*kstar.AdjacencyGraph, error) {
+	name, r := "stdin", io.Reader(os.Stdin)
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		// the file is only read, closing it cannot lose data
+		defer file.Close()
+		name, r = path, file
+	}
+
+	var g *kstar.AdjacencyGraph
+	var err error
+	switch format {
+	case "graph":
+		g, err = graphio.Read(r)
+	case "dot":
+		g, _, err = graphio.DOT{Attributes: attrs}.Read(r)
+	case "graphml":
+		g, _, err = graphio.GraphML{Attributes: attrs}.Read(r)
+	default:
+		return nil, fmt.Errorf("unknown input format %q", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return g, nil
 }
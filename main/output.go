@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jcasado94/kstar"
+	"github.com/jcasado94/kstar/graphio"
+)
+
+// answer holds a graph and the paths found in it.
+type answer struct {
+	graphID string
+	g       *kstar.AdjacencyGraph
+	k       int
+	paths   [][]kstar.Edge
+	stats   kstar.Stats
+}
+
+func (q answer) result() *kstar.Result {
+	return kstar.NewResult(q.graphID, q.g, q.k, q.paths, &q.stats)
+}
+
+type formatter func(w io.Writer, q answer) error
+
+var formatters = map[string]formatter{
+	"text":    writeText,
+	"json":    writeJSON,
+	"dot":     writeDot,
+	"graphml": writeGraphML,
+}
+
+// writeText writes one line per path with its rank, its cost and its edges.
+// Parallel edges other than the first one are marked with their index.
+func writeText(w io.Writer, q answer) error {
+	res := q.result()
+	for _, pr := range res.Paths {
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "%d\t%g\t%d", pr.Rank, pr.Cost, res.S)
+		for _, e := range pr.Edges {
+			if e.I == 0 {
+				fmt.Fprintf(&sb, " -> %d", e.V)
+			} else {
+				fmt.Fprintf(&sb, " -[%d]-> %d", e.I, e.V)
+			}
+		}
+		for _, st := range pr.Sidetracks {
+			fmt.Fprintf(&sb, "\n\t+%g\t%d -> %d", st.Detour, st.Edge.U, st.Edge.V)
+			if st.Instead != nil {
+				fmt.Fprintf(&sb, " instead of %d -> %d", st.Instead.U, st.Instead.V)
+			}
+		}
+		if _, err := fmt.Fprintln(w, sb.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeJSON writes the result of the query in the JSON schema of kstar.Result.
+func writeJSON(w io.Writer, q answer) error {
+	return kstar.EncodeResult(w, q.result())
+}
+
+// writeDot writes the whole graph in the DOT language with the paths highlighted.
+func writeDot(w io.Writer, q answer) error {
+	return graphio.WriteDOT(w, q.g, q.paths)
+}
+
+// writeGraphML writes the whole graph in the GraphML format with the paths highlighted.
+func writeGraphML(w io.Writer, q answer) error {
+	return graphio.WriteGraphML(w, q.g, q.paths)
+}
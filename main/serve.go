@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jcasado94/kstar"
+	"github.com/jcasado94/kstar/graphio"
+	"github.com/jcasado94/kstar/server"
+)
+
+// graphFlags collects the name=path values of the -graph flag of serve.
+type graphFlags map[string]string
+
+func (gf graphFlags) String() string {
+	pairs := make([]string, 0, len(gf))
+	for name, path := range gf {
+		pairs = append(pairs, name+"="+path)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (gf graphFlags) Set(value string) error {
+	name, path := value, value
+	if i := strings.IndexByte(value, '='); i >= 0 {
+		name, path = value[:i], value[i+1:]
+	}
+	if name == "" || path == "" {
+		return fmt.Errorf("expected name=path, found %q", value)
+	}
+	if _, ok := gf[name]; ok {
+		return fmt.Errorf("duplicate graph %q", name)
+	}
+	gf[name] = path
+	return nil
+}
+
+// serve loads the graphs given as arguments and answers queries on them over HTTP, see package server.
+//
+//	kstar serve [-addr :8080] -graph name=file [-graph name=file ...] [-timeout 30s] [-max-k 10000]
+func serve(args []string) {
+	fs := flag.NewFlagSet("kstar serve", flag.ExitOnError)
+	graphs := make(graphFlags)
+	fs.Var(graphs, "graph", "graph to serve as name=file, repeatable; the name defaults to the file")
+	addr := fs.String("addr", ":8080", "address to listen on")
+	costAttr := fs.String("cost-attr", "", "attribute holding edge costs in dot and graphml files (default cost)")
+	timeout := fs.Duration("timeout", 30*time.Second, "longest time a query may run")
+	maxK := fs.Int("max-k", 10000, "largest k a query may ask for")
+	fs.Parse(args)
+
+	if len(graphs) == 0 {
+		log.Fatal("no graph to serve, set -graph")
+	}
+	loaded := make(map[string]*kstar.AdjacencyGraph, len(graphs))
+	for name, path := range graphs {
+		g, err := loadGraph(path, inputFormat(path), graphio.Attributes{Cost: *costAttr})
+		if err != nil {
+			log.Fatal(err)
+		}
+		loaded[name] = g
+		log.Printf("loaded %s from %s: %d nodes, %d edges", name, path, g.NumNodes(), g.NumEdges())
+	}
+
+	srv := server.New(loaded, server.Config{Timeout: *timeout, MaxK: *maxK})
+	log.Printf("listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, srv))
+}
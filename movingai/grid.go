@@ -0,0 +1,129 @@
+package movingai
+
+import (
+	"math"
+
+	"github.com/jcasado94/kstar/geo"
+)
+
+// Connectivity is the set of moves allowed from a cell.
+type Connectivity int
+
+const (
+	// Octile allows moving to the 8 neighbouring cells, diagonal moves costing sqrt(2).
+	Octile Connectivity = iota
+	// Cardinal allows moving to the 4 neighbouring cells in the cardinal directions.
+	Cardinal
+)
+
+// CornerRule decides which diagonal moves are allowed next to blocked cells.
+type CornerRule int
+
+const (
+	// NoCornerCutting allows a diagonal move only if both cells it passes by are passable, as in the MovingAI benchmarks.
+	NoCornerCutting CornerRule = iota
+	// CornerCutting allows a diagonal move if at least one of the cells it passes by is passable.
+	CornerCutting
+	// Squeezing allows every diagonal move between passable cells.
+	Squeezing
+)
+
+// GridOptions configures the graph of a Map.
+type GridOptions struct {
+	Connectivity Connectivity
+	Corners      CornerRule
+}
+
+// Grid is the implicit graph of the passable cells of a Map, between two of them. Its FValue is the octile distance
+// to T() for Octile connectivity and the Manhattan distance for Cardinal, both admissible and consistent.
+// Grid implements EdgeCoster.
+type Grid struct {
+	m    *Map
+	opts GridOptions
+	s, t int
+}
+
+type move struct {
+	dx, dy int
+}
+
+var moves = []move{{1, 0}, {0, 1}, {-1, 0}, {0, -1}, {1, 1}, {1, -1}, {-1, 1}, {-1, -1}}
+
+// Grid returns the graph of m going from the cell at sx, sy to the one at tx, ty.
+func (m *Map) Grid(opts GridOptions, sx, sy, tx, ty int) *Grid {
+	return &Grid{m: m, opts: opts, s: m.Node(sx, sy), t: m.Node(tx, ty)}
+}
+
+// Connections returns the moves allowed from the cell of node n.
+func (g *Grid) Connections(n int) map[int][]float64 {
+	x, y := g.m.Cell(n)
+	conns := make(map[int][]float64)
+	if !g.m.Passable(x, y) {
+		return conns
+	}
+	for _, mv := range g.moves() {
+		if g.allowed(x, y, mv) {
+			conns[g.m.Node(x+mv.dx, y+mv.dy)] = []float64{moveCost(mv)}
+		}
+	}
+	return conns
+}
+
+// EdgeCost returns the cost of the move from u to v.
+func (g *Grid) EdgeCost(u, v, i int) float64 {
+	ux, uy := g.m.Cell(u)
+	vx, vy := g.m.Cell(v)
+	return moveCost(move{vx - ux, vy - uy})
+}
+
+// S returns the node of the start cell.
+func (g *Grid) S() int {
+	return g.s
+}
+
+// T returns the node of the goal cell.
+func (g *Grid) T() int {
+	return g.t
+}
+
+// FValue returns the octile or Manhattan distance from n to T().
+func (g *Grid) FValue(n int) float64 {
+	x, y := g.m.Cell(n)
+	tx, ty := g.m.Cell(g.t)
+	metric := geo.Octile
+	if g.opts.Connectivity == Cardinal {
+		metric = geo.Manhattan
+	}
+	return metric.Distance(geo.Point{X: float64(x), Y: float64(y)}, geo.Point{X: float64(tx), Y: float64(ty)})
+}
+
+func (g *Grid) moves() []move {
+	if g.opts.Connectivity == Cardinal {
+		return moves[:4]
+	}
+	return moves
+}
+
+func (g *Grid) allowed(x, y int, mv move) bool {
+	if !g.m.Passable(x+mv.dx, y+mv.dy) {
+		return false
+	}
+	if mv.dx == 0 || mv.dy == 0 {
+		return true
+	}
+	sideX, sideY := g.m.Passable(x+mv.dx, y), g.m.Passable(x, y+mv.dy)
+	switch g.opts.Corners {
+	case CornerCutting:
+		return sideX || sideY
+	case Squeezing:
+		return true
+	}
+	return sideX && sideY
+}
+
+func moveCost(mv move) float64 {
+	if mv.dx != 0 && mv.dy != 0 {
+		return math.Sqrt2
+	}
+	return 1
+}
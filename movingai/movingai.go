@@ -0,0 +1,128 @@
+// Package movingai turns the grid maps of the MovingAI benchmarks into graphs for K* and runs their scenarios.
+//
+// A .map file has a header followed by the grid, one character per cell:
+//
+//	type octile
+//	height <h>
+//	width <w>
+//	map
+//	<h lines of w cells>
+//
+// Cells . and G are passable ground and S is passable swamp. Any other cell, such as @, O, T or W, blocks movement.
+package movingai
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jcasado94/kstar/graphio"
+)
+
+// Map is a grid of cells read from a .map file.
+type Map struct {
+	Width, Height int
+	cells         [][]byte
+}
+
+// ReadMapFile reads a .map file.
+func ReadMapFile(path string) (*Map, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	m, err := ReadMap(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+// ReadMap reads a grid map in the .map format. Malformed lines are reported as *graphio.ParseError.
+func ReadMap(r io.Reader) (*Map, error) {
+	m := &Map{Width: -1, Height: -1}
+	scanner := bufio.NewScanner(r)
+	line := 0
+	lineErr := func(err error) error {
+		return &graphio.ParseError{Line: line, Err: err}
+	}
+
+	for scanner.Scan() {
+		line++
+		vals := strings.Fields(scanner.Text())
+		if len(vals) == 0 {
+			continue
+		}
+		if vals[0] == "map" {
+			break
+		}
+		if len(vals) != 2 {
+			return nil, lineErr(fmt.Errorf("malformed header line %q", scanner.Text()))
+		}
+		switch vals[0] {
+		case "type":
+		case "height", "width":
+			num, err := strconv.Atoi(vals[1])
+			if err != nil {
+				return nil, lineErr(err)
+			}
+			if num <= 0 {
+				return nil, lineErr(fmt.Errorf("invalid %s %d", vals[0], num))
+			}
+			if vals[0] == "height" {
+				m.Height = num
+			} else {
+				m.Width = num
+			}
+		default:
+			return nil, lineErr(fmt.Errorf("unknown header %q", vals[0]))
+		}
+	}
+	if m.Width < 0 || m.Height < 0 {
+		return nil, errors.New("missing width or height")
+	}
+
+	m.cells = make([][]byte, 0, m.Height)
+	for len(m.cells) < m.Height && scanner.Scan() {
+		line++
+		row := strings.TrimRight(scanner.Text(), "\r")
+		if len(row) != m.Width {
+			return nil, lineErr(fmt.Errorf("expected %d cells, found %d", m.Width, len(row)))
+		}
+		m.cells = append(m.cells, []byte(row))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(m.cells) != m.Height {
+		return nil, fmt.Errorf("expected %d rows, found %d", m.Height, len(m.cells))
+	}
+	return m, nil
+}
+
+// Passable reports whether the cell at x, y is inside the map and can be traversed.
+func (m *Map) Passable(x, y int) bool {
+	if x < 0 || y < 0 || x >= m.Width || y >= m.Height {
+		return false
+	}
+	switch m.cells[y][x] {
+	case '.', 'G', 'S':
+		return true
+	}
+	return false
+}
+
+// Node returns the graph node of the cell at x, y.
+func (m *Map) Node(x, y int) int {
+	return y*m.Width + x
+}
+
+// Cell returns the coordinates of the cell of node n.
+func (m *Map) Cell(n int) (x, y int) {
+	return n % m.Width, n / m.Width
+}
@@ -0,0 +1,94 @@
+package movingai
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+const (
+	testMap = `type octile
+height 3
+width 4
+map
+....
+.@@.
+....
+`
+	testScenarios = `version 1
+0	test.map	4	3	0	0	3	2	5.00000000
+0	test.map	4	3	0	1	3	1	5.00000000
+0	test.map	4	3	1	1	3	1	0
+`
+)
+
+func TestRunScenarios(t *testing.T) {
+	m, err := ReadMap(strings.NewReader(testMap))
+	if err != nil {
+		t.Fatal(err)
+	}
+	scens, err := ReadScenarios(strings.NewReader(testScenarios))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scens) != 3 {
+		t.Fatalf("Expected 3 scenarios, but found %d.", len(scens))
+	}
+
+	results := RunScenarios(m, scens, 3, GridOptions{})
+	for _, res := range results[:2] {
+		if res.Err != nil || !res.Optimal {
+			t.Errorf("Scenario %+v failed: %+v", res.Scenario, res)
+		}
+		for i := 1; i < len(res.Paths); i++ {
+			if res.Paths[i] < res.Paths[i-1] {
+				t.Errorf("Scenario %+v paths not sorted: %v", res.Scenario, res.Paths)
+			}
+		}
+	}
+	if results[2].Err == nil {
+		t.Error("Expected an error for a scenario starting on a blocked cell.")
+	}
+}
+
+func TestGridOptions(t *testing.T) {
+	m, err := ReadMap(strings.NewReader(testMap))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		opts    GridOptions
+		optimal float64
+	}{
+		{GridOptions{Connectivity: Octile, Corners: NoCornerCutting}, 5},
+		{GridOptions{Connectivity: Octile, Corners: CornerCutting}, 3 + math.Sqrt2},
+		{GridOptions{Connectivity: Cardinal}, 5},
+	}
+	for _, test := range tests {
+		res := runScenario(m, Scenario{Width: 4, Height: 3, GoalX: 3, GoalY: 2, Optimal: test.optimal}, 1, test.opts)
+		if !res.Optimal {
+			t.Errorf("Options %+v: expected cost %f, but found %v.", test.opts, test.optimal, res.Paths)
+		}
+	}
+
+	g := m.Grid(GridOptions{Corners: Squeezing}, 0, 0, 3, 2)
+	if _, ok := g.Connections(m.Node(1, 0))[m.Node(2, 1)]; ok {
+		t.Error("Moves to blocked cells must not be allowed.")
+	}
+	if _, ok := g.Connections(m.Node(0, 1))[m.Node(1, 2)]; !ok {
+		t.Error("Squeezing must allow diagonal moves next to blocked cells.")
+	}
+}
+
+func TestReadMapErrors(t *testing.T) {
+	inputs := []string{
+		"height 2\nwidth 2\nmap\n..\n",
+		"height 2\nwidth 2\nmap\n..\n...\n",
+		"width 2\nmap\n..\n",
+	}
+	for _, input := range inputs {
+		if _, err := ReadMap(strings.NewReader(input)); err == nil {
+			t.Errorf("Expected an error reading %q.", input)
+		}
+	}
+}
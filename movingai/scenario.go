@@ -0,0 +1,147 @@
+package movingai
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jcasado94/kstar"
+	"github.com/jcasado94/kstar/graphio"
+)
+
+// optimalTolerance is the difference allowed between the cost found and the optimal length of a scenario,
+// which is rounded in the .scen files.
+const optimalTolerance = 1e-4
+
+// Scenario is a query of a .scen file.
+type Scenario struct {
+	Bucket                       int
+	Map                          string
+	Width, Height                int
+	StartX, StartY, GoalX, GoalY int
+	Optimal                      float64
+}
+
+// ReadScenarioFile reads a .scen file.
+func ReadScenarioFile(path string) ([]Scenario, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	scens, err := ReadScenarios(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return scens, nil
+}
+
+// ReadScenarios reads the scenarios of a .scen file, one per line after the optional version line:
+//
+//	<bucket> <map> <width> <height> <start x> <start y> <goal x> <goal y> <optimal length>
+//
+// Malformed lines are reported as *graphio.ParseError.
+func ReadScenarios(r io.Reader) ([]Scenario, error) {
+	scens := make([]Scenario, 0)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		vals := strings.Fields(scanner.Text())
+		if len(vals) == 0 || vals[0] == "version" {
+			continue
+		}
+		scen, err := parseScenario(vals)
+		if err != nil {
+			return nil, &graphio.ParseError{Line: line, Err: err}
+		}
+		scens = append(scens, scen)
+	}
+	return scens, scanner.Err()
+}
+
+func parseScenario(vals []string) (scen Scenario, err error) {
+	if len(vals) != 9 {
+		return scen, fmt.Errorf("expected 9 values, found %d", len(vals))
+	}
+	ints := make([]int, 0, 7)
+	for i, val := range vals[:8] {
+		if i == 1 {
+			continue
+		}
+		num, err := strconv.Atoi(val)
+		if err != nil {
+			return scen, err
+		}
+		ints = append(ints, num)
+	}
+	optimal, err := strconv.ParseFloat(vals[8], 64)
+	if err != nil {
+		return scen, err
+	}
+	return Scenario{
+		Bucket:  ints[0],
+		Map:     vals[1],
+		Width:   ints[1],
+		Height:  ints[2],
+		StartX:  ints[3],
+		StartY:  ints[4],
+		GoalX:   ints[5],
+		GoalY:   ints[6],
+		Optimal: optimal,
+	}, nil
+}
+
+// ScenarioResult is the outcome of running K* on a Scenario.
+type ScenarioResult struct {
+	Scenario Scenario
+	// Paths holds the costs of the paths found.
+	Paths []float64
+	// Optimal reports whether the first path costs the optimal length of the scenario.
+	Optimal bool
+	// Duration is the time K* took.
+	Duration time.Duration
+	// Err is set if the scenario could not be run.
+	Err error
+}
+
+// RunScenarios runs K* for k paths on every scenario, in order, and checks the cost of the first path against the
+// optimal length of the scenario. The optimal lengths of the MovingAI benchmarks assume Octile connectivity and
+// NoCornerCutting.
+func RunScenarios(m *Map, scens []Scenario, k int, opts GridOptions) []ScenarioResult {
+	results := make([]ScenarioResult, len(scens))
+	for i, scen := range scens {
+		results[i] = runScenario(m, scen, k, opts)
+	}
+	return results
+}
+
+func runScenario(m *Map, scen Scenario, k int, opts GridOptions) (res ScenarioResult) {
+	res.Scenario = scen
+	if scen.Width != m.Width || scen.Height != m.Height {
+		res.Err = fmt.Errorf("scenario is for a %dx%d map, not %dx%d", scen.Width, scen.Height, m.Width, m.Height)
+		return
+	}
+	if !m.Passable(scen.StartX, scen.StartY) || !m.Passable(scen.GoalX, scen.GoalY) {
+		res.Err = errors.New("start or goal cell not passable")
+		return
+	}
+
+	g := m.Grid(opts, scen.StartX, scen.StartY, scen.GoalX, scen.GoalY)
+	start := time.Now()
+	paths := kstar.Run(g, k)
+	res.Duration = time.Since(start)
+
+	res.Paths = make([]float64, len(paths))
+	for i, path := range paths {
+		for _, e := range path {
+			res.Paths[i] += g.EdgeCost(e.U, e.V, e.I)
+		}
+	}
+	res.Optimal = len(paths) > 0 && math.Abs(res.Paths[0]-scen.Optimal) <= optimalTolerance*math.Max(1, scen.Optimal)
+	return
+}
@@ -0,0 +1,42 @@
+package kstar
+
+// Observer receives the events of a K* search, for tracing and visualisation.
+// Callbacks are invoked synchronously from the goroutine running the query, so they should return quickly.
+type Observer interface {
+
+	// NodeExpanded is called when A* expands n, g being its cost from S(). reopened is true if n had been closed before.
+	NodeExpanded(n int, g float64, reopened bool)
+
+	// SidetrackAdded is called once for every sidetrack edge e, when A* first finds it, d being its sidetrack cost then.
+	// The path graph is updated after every resumption of A*, which may change d, or turn e into a tree edge and back,
+	// without calling SidetrackAdded again.
+	SidetrackAdded(e Edge, d float64)
+
+	// AstarResumed is called every time A* is resumed, resumption counting from 1.
+	AstarResumed(resumption int)
+
+	// PathGraphNodePopped is called when Dijkstra pops the path graph node of sidetrack edge e with key cost.
+	// The root R of the path graph is reported with every key of e set to -1.
+	PathGraphNodePopped(e Edge, cost float64)
+
+	// PathEmitted is called with every returned path, rank counting from 1.
+	PathEmitted(rank int, path []Edge)
+}
+
+// NopObserver implements Observer ignoring every event. Embed it to implement only some of the callbacks.
+type NopObserver struct{}
+
+// NodeExpanded does nothing.
+func (NopObserver) NodeExpanded(n int, g float64, reopened bool) {}
+
+// SidetrackAdded does nothing.
+func (NopObserver) SidetrackAdded(e Edge, d float64) {}
+
+// AstarResumed does nothing.
+func (NopObserver) AstarResumed(resumption int) {}
+
+// PathGraphNodePopped does nothing.
+func (NopObserver) PathGraphNodePopped(e Edge, cost float64) {}
+
+// PathEmitted does nothing.
+func (NopObserver) PathEmitted(rank int, path []Edge) {}
@@ -0,0 +1,145 @@
+package kstar
+
+import "io"
+
+// Option configures a single query run by RunWithOptions or RunContext.
+type Option func(*options)
+
+type options struct {
+	observer    Observer
+	policy      ResumptionPolicy
+	loopless    bool
+	maxExplored int
+	costBound   *float64
+	forbidden   map[Edge]bool
+	weight      float64
+	pruneDead   bool
+	explain     bool
+	dump        io.Writer
+	dumpDepth   int
+	checks      bool
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithObserver registers obs to receive the events of the search. No events are produced without an observer.
+func WithObserver(obs Observer) Option {
+	return func(o *options) {
+		o.observer = obs
+	}
+}
+
+// WithResumptionPolicy sets the policy deciding when a resumed A* is interrupted. DoublingPolicy is used by default.
+func WithResumptionPolicy(p ResumptionPolicy) Option {
+	return func(o *options) {
+		o.policy = p
+	}
+}
+
+// WithLoopless only returns paths without loops. Paths with loops are skipped but count towards maxExplored, the
+// number of paths examined at most, 0 for no limit. Since a graph with cycles has infinitely many paths with loops,
+// a query asking for more loopless paths than there are only ends at the limit, at the cost bound or when its
+// context is done.
+func WithLoopless(maxExplored int) Option {
+	return func(o *options) {
+		o.loopless = true
+		o.maxExplored = maxExplored
+	}
+}
+
+// WithCostBound only returns paths costing at most bound.
+func WithCostBound(bound float64) Option {
+	return func(o *options) {
+		o.costBound = &bound
+	}
+}
+
+// WithForbiddenEdges leaves edges out of the search, as if they were not in the graph. The indices of the other
+// parallel edges do not change.
+func WithForbiddenEdges(edges ...Edge) Option {
+	return func(o *options) {
+		if o.forbidden == nil {
+			o.forbidden = make(map[Edge]bool, len(edges))
+		}
+		for _, e := range edges {
+			o.forbidden[e] = true
+		}
+	}
+}
+
+// WithWeight orders the nodes of A* by g + w·h instead of g + h, trading the order of the paths for fewer
+// expansions. Weights below 1 are taken as 1.
+//
+// Provided the heuristic is admissible, the ith returned path costs at most w times the exact ith shortest path, and
+// Stats.LowerBounds[i] bounds the cost of the latter from below, so that the ith path is within a factor of its cost
+// over LowerBounds[i], at most w, of it. The paths are no longer returned in cost order: a path may be followed by a
+// cheaper one, costing at least 1/w of it. WithCostBound may leave out paths costing more than bound/w.
+func WithWeight(w float64) Option {
+	return func(o *options) {
+		o.weight = w
+	}
+}
+
+// WithDeadEndPruning leaves the nodes that cannot reach T() out of the search. They are found before the search,
+// going backwards from T(), and a query whose S() is among them returns no paths right away. The time it takes counts
+// as A* time. Queries on a Graph that is not a ReverseGraph fail with ErrNotReverseGraph, see WrapGraph for the ones
+// built on a ReverseGraph. Sessions ignore it, since their search serves several targets.
+func WithDeadEndPruning() Option {
+	return func(o *options) {
+		o.pruneDead = true
+	}
+}
+
+// WithExplanations describes every returned path in Stats.Explanations as the shortest path of the search tree with
+// detours, see Explanation.
+func WithExplanations() Option {
+	return func(o *options) {
+		o.explain = true
+	}
+}
+
+// WithPathGraphDump writes the path graph to w in the DOT language when the query ends, for debugging: its H_in and
+// H_T heaps, the sidetrack and d value of each of their nodes, and the cross and heap edges Dijkstra follows, with the
+// keys they add. Only the nodes within depth edges of its root R are written, all of them if depth is not positive.
+// An error writing to w is returned as the error of the query, unless it was interrupted.
+func WithPathGraphDump(w io.Writer, depth int) Option {
+	return func(o *options) {
+		o.dump, o.dumpDepth = w, depth
+	}
+}
+
+// WithInvariantChecks checks the internal structures of K* as the query runs, for debugging: the order and position
+// index of every heap of the path graph and their agreement with the search tree of A* after every rebuild, and the
+// cost of every path against its Dijkstra key. A violation panics with an *InvariantError listing all of them.
+// The checks take time linear in the size of the path graph at every rebuild.
+func WithInvariantChecks() Option {
+	return func(o *options) {
+		o.checks = true
+	}
+}
+
+// apply configures the K* instance of a query.
+func (o *options) apply(ks *kstar) {
+	ks.obs = o.observer
+	ks.as.obs = o.observer
+	ks.pg.obs = o.observer
+	ks.d.obs = o.observer
+	ks.as.c.policy = o.policy
+	ks.as.forbidden = o.forbidden
+	ks.loopless, ks.maxExplored = o.loopless, o.maxExplored
+	ks.pruneDead, ks.explain = o.pruneDead, o.explain
+	ks.dump, ks.dumpDepth = o.dump, o.dumpDepth
+	ks.checks = o.checks
+	if o.weight > 1 {
+		ks.as.weight = o.weight
+	}
+	if o.costBound != nil {
+		ks.costBound = *o.costBound
+	}
+}
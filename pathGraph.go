@@ -3,9 +3,13 @@ package kstar
 import "container/heap"
 
 type pathGraph struct {
-	hin map[int]*pathGraphHeap
-	ht  map[int]*pathGraphHeap
-	r   rNode
+	hin   map[int]*pathGraphHeap
+	ht    map[int]*pathGraphHeap
+	r     rNode
+	into  map[int][]Edge // every edge found by A* that is or has been a sidetrack, by head, in discovery order
+	outOf map[int][]Edge // the same edges by tail
+	found map[Edge]bool  // set of sidetracks
+	obs   Observer       // nil if no observer is registered
 }
 
 func newPathGraph() *pathGraph {
@@ -14,58 +18,104 @@ func newPathGraph() *pathGraph {
 	pg.hin = make(map[int]*pathGraphHeap)
 	pg.ht = make(map[int]*pathGraphHeap)
 	pg.r = rNode{}
+	pg.into = make(map[int][]Edge)
+	pg.outOf = make(map[int][]Edge)
+	pg.found = make(map[Edge]bool)
 
 	return &pg
 }
 
-func (pg *pathGraph) updateHinNodes(edges []Edge, as *astar) {
+// updateHinNodes adds the edges found by A* and rebuilds the H_in heaps that may have changed since the last update:
+// the ones the new edges arrive at, the ones of the nodes A* moved in the search tree, and the ones of the edges
+// leaving those nodes. d values are recomputed, since reopenings may have changed the g values, and edges that have
+// become part of the search tree are left out. It returns the nodes whose H_in root changed.
+func (pg *pathGraph) updateHinNodes(edges []Edge, as *astar) (rootChanged map[int]bool) {
+	dirty := make(map[int]bool)
 	for _, e := range edges {
-		if _, ok := pg.hin[e.V]; !ok {
-			pg.hin[e.V] = newPathGraphHeap()
+		if !pg.found[e] {
+			pg.found[e] = true
+			pg.into[e.V] = append(pg.into[e.V], e)
+			pg.outOf[e.U] = append(pg.outOf[e.U], e)
+			if pg.obs != nil {
+				pg.obs.SidetrackAdded(e, as.dValue(e))
+			}
 		}
-		hin := pg.hin[e.V]
-		n := hinNode{
+		dirty[e.V] = true
+	}
+	for n := range as.changed {
+		dirty[n] = true
+		for _, e := range pg.outOf[n] {
+			dirty[e.V] = true
+		}
+	}
+
+	rootChanged = make(map[int]bool)
+	for v := range dirty {
+		if pg.updateHin(v, as) {
+			rootChanged[v] = true
+		}
+	}
+	return rootChanged
+}
+
+// updateHin rebuilds H_in(v) in place, so that the H_T heaps sharing its root keep pointing to it, or drops it when
+// no sidetrack arrives at v. It reports whether the root of the heap changed.
+func (pg *pathGraph) updateHin(v int, as *astar) (rootChanged bool) {
+	hin, ok := pg.hin[v]
+	var oldRoot *hinNode
+	if ok {
+		root := hin.Top().(hinNode)
+		oldRoot = &root
+	} else {
+		hin = newPathGraphHeap()
+	}
+
+	hin.pq = hin.pq[:0]
+	for _, e := range pg.into[v] {
+		if as.searchTreeParents[v] == e && v != as.g.S() {
+			continue
+		}
+		hin.pq = append(hin.pq, hinNode{
 			u:    e.U,
 			v:    e.V,
 			i:    e.I,
 			d:    as.dValue(e),
 			vHin: hin,
 			hts:  &pg.ht,
-		}
-
-		currentTop := hin.Top()
-		exists, pos := hin.exists(n.EdgeKeys())
-		if exists {
-			hin.replace(hin.pq[pos], n)
-			heap.Fix(hin, pos)
-		} else {
-			heap.Push(hin, n)
-		}
-		top := hin.Top().(hinNode)
-
-		if currentTop != nil && !currentTop.(hinNode).equals(top) {
-			currentTopHin := currentTop.(hinNode)
-			pg.propagateHinTopChange(&currentTopHin, &top, as)
-		}
-
+		})
+	}
+	if hin.Empty() {
+		delete(pg.hin, v)
+		return oldRoot != nil
 	}
+	hin.layoutHin()
+	pg.hin[v] = hin
+	return oldRoot == nil || !oldRoot.equals(hin.Top().(hinNode))
 }
 
-func (pg *pathGraph) propagateHinTopChange(oldNode, newNode *hinNode, as *astar) {
-	current := as.g.T()
-	currentHt := pg.ht[current]
-	for ok, pos := currentHt.exists(oldNode.u, oldNode.v, oldNode.i); ok; {
-		currentHt.replace(oldNode, newNode)
-		heap.Fix(currentHt, pos)
-		current = as.searchTreeParents[current].U
-		currentHt = pg.ht[current]
+// updateHts regenerates the H_T heaps that may have changed since the last update: the ones of the nodes A* added
+// to or moved in the search tree and of the nodes whose H_in root changed, along with their subtrees. R is pointed to
+// the H_T heap of T().
+func (pg *pathGraph) updateHts(as *astar, rootChanged map[int]bool) {
+	stale := func(n int) bool {
+		return as.changed[n] || rootChanged[n]
 	}
+	pg.updateHt(as.g.S(), as.g.S(), stale, as)
+	pg.r.tHt = pg.ht[as.g.T()]
 }
 
-func (pg *pathGraph) generateHts(as *astar) {
-	pg.generateHt(as.g.S(), as.g.S(), as)
+// updateHt regenerates the subtrees of the search tree under n whose root is stale.
+func (pg *pathGraph) updateHt(n, s int, stale func(n int) bool, as *astar) {
+	if stale(n) {
+		pg.generateHt(n, s, as)
+		return
+	}
+	for child := range as.searchTreeChildren[n] {
+		pg.updateHt(child, s, stale, as)
+	}
 }
 
+// generateHt builds the H_T heaps of n and of its subtree in the search tree.
 func (pg *pathGraph) generateHt(n, s int, as *astar) {
 
 	if n == s {
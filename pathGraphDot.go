@@ -0,0 +1,98 @@
+package kstar
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// writeDOT writes the path graph in the DOT language, as Dijkstra walks it from R: every node is labelled with its
+// sidetrack and d value and drawn in the cluster of its H_in or H_T heap, and every edge with its type, cross or
+// heap, and the key it adds. Only the nodes within depth edges of R are written, all of them if depth is not
+// positive.
+func (pg *pathGraph) writeDOT(w io.Writer, depth int) error {
+	owners := make(map[*pathGraphHeap]int, len(pg.ht))
+	for n, ht := range pg.ht {
+		owners[ht] = n
+	}
+
+	type dotNode struct {
+		id, label, heap string
+	}
+	type dotEdge struct {
+		from, to, kind string
+		cost           float64
+	}
+	var nodes []dotNode
+	var edges []dotEdge
+	seen := map[string]bool{"R": true}
+	describe := func(n pathGraphNode) dotNode {
+		u, v, i := n.EdgeKeys()
+		label := fmt.Sprintf("%d -> %d [%d]\\nd=%g", u, v, i, n.D())
+		if htn, ok := n.(htNode); ok {
+			owner := owners[htn.ht]
+			return dotNode{fmt.Sprintf("t%d_%d_%d_%d", owner, u, v, i), label, fmt.Sprintf("H_T(%d)", owner)}
+		}
+		return dotNode{fmt.Sprintf("in_%d_%d_%d", u, v, i), label, fmt.Sprintf("H_in(%d)", v)}
+	}
+
+	// breadth first from R, following the edges of dijkstra.pushChildren
+	level := []pathGraphNode{pg.r}
+	ids := []string{"R"}
+	if pg.r.tHt == nil {
+		level = nil
+	}
+	for steps := 0; len(level) > 0 && (depth <= 0 || steps < depth); steps++ {
+		var next []pathGraphNode
+		var nextIDs []string
+		visit := func(from string, c pathGraphNode, kind string, cost float64) {
+			dn := describe(c)
+			edges = append(edges, dotEdge{from, dn.id, kind, cost})
+			if !seen[dn.id] {
+				seen[dn.id] = true
+				nodes = append(nodes, dn)
+				next, nextIDs = append(next, c), append(nextIDs, dn.id)
+			}
+		}
+		for j, n := range level {
+			if c := n.CrossEdgeChild(); c != nil {
+				visit(ids[j], c, "cross", c.D())
+			}
+			for _, c := range n.HeapEdgeChildren() {
+				visit(ids[j], c, "heap", c.D()-n.D())
+			}
+		}
+		level, ids = next, nextIDs
+	}
+
+	heaps := make(map[string][]dotNode)
+	names := make([]string, 0)
+	for _, dn := range nodes {
+		if _, ok := heaps[dn.heap]; !ok {
+			names = append(names, dn.heap)
+		}
+		heaps[dn.heap] = append(heaps[dn.heap], dn)
+	}
+	sort.Strings(names)
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "digraph pathGraph {\n")
+	fmt.Fprintf(bw, "\tR [shape=doublecircle];\n")
+	for c, name := range names {
+		fmt.Fprintf(bw, "\tsubgraph cluster_%d {\n\t\tlabel=\"%s\";\n", c, name)
+		for _, dn := range heaps[name] {
+			fmt.Fprintf(bw, "\t\t%s [label=\"%s\"];\n", dn.id, dn.label)
+		}
+		fmt.Fprintf(bw, "\t}\n")
+	}
+	for _, e := range edges {
+		style := "solid"
+		if e.kind == "heap" {
+			style = "dashed"
+		}
+		fmt.Fprintf(bw, "\t%s -> %s [type=%s, label=\"%s +%g\", style=%s];\n", e.from, e.to, e.kind, e.kind, e.cost, style)
+	}
+	fmt.Fprintf(bw, "}\n")
+	return bw.Flush()
+}
@@ -0,0 +1,42 @@
+package kstar
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPathGraphDump(t *testing.T) {
+	var buf bytes.Buffer
+	RunWithOptions(newDiamondGraph(), 10, WithPathGraphDump(&buf, 0))
+	out := buf.String()
+	for _, want := range []string{
+		"digraph pathGraph {",
+		`label="H_T(3)";`,
+		`label="H_in(3)";`,
+		`t3_2_3_0 [label="2 -> 3 [0]\nd=0"];`,
+		`R -> t3_2_3_0 [type=cross, label="cross +0", style=solid];`,
+		`t3_2_3_0 -> in_2_3_1 [type=heap, label="heap +3", style=dashed];`,
+		`t3_2_3_0 -> t3_0_1_1 [type=heap, label="heap +2", style=dashed];`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in the dump, but found\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	RunWithOptions(newDiamondGraph(), 10, WithPathGraphDump(&buf, 1))
+	if n := strings.Count(buf.String(), " -> t") + strings.Count(buf.String(), " -> in"); n != 1 {
+		t.Errorf("Expected 1 edge within 1 step of R, but found %d in\n%s", n, buf.String())
+	}
+
+	if _, _, err := RunContext(context.Background(), newDiamondGraph(), 10, WithPathGraphDump(failingWriter{}, 0)); err == nil {
+		t.Error("Expected the error of the writer, but found none.")
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) { return 0, errors.New("write failed") }
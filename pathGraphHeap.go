@@ -73,19 +73,41 @@ func (h pathGraphHeap) Top() interface{} {
 	return h.pq[0]
 }
 
-func (h pathGraphHeap) exists(u, v, i int) (exists bool, pos int) {
-	if h.nodes.exists(u, v, i) {
-		return true, h.nodes[u][v][i]
+// layoutHin arranges the nodes of an H_in heap in linear time: the one with the lowest d at the root, with a single
+// child heading a heap of the rest, where the children of position p are 2p and 2p+1, see hinNode.getLeftChild.
+func (h *pathGraphHeap) layoutHin() {
+	min := 0
+	for pos := range h.pq {
+		if h.pq[pos].D() < h.pq[min].D() {
+			min = pos
+		}
+	}
+	h.pq[0], h.pq[min] = h.pq[min], h.pq[0]
+	for pos := (len(h.pq) - 1) / 2; pos >= 1; pos-- {
+		h.siftDownHin(pos)
+	}
+	h.nodes = set{}
+	for pos, n := range h.pq {
+		u, v, i := n.EdgeKeys()
+		h.nodes.put(pos, u, v, i)
 	}
-	return false, -1
 }
 
-func (h *pathGraphHeap) replace(oldNode, newNode pathGraphNode) {
-	uOld, vOld, iOld := oldNode.EdgeKeys()
-	uNew, vNew, iNew := newNode.EdgeKeys()
-	pos := h.nodes[uOld][vOld][iOld]
-	h.nodes.put(pos, uNew, vNew, iNew)
-	h.pq[pos] = newNode
+// siftDownHin moves the node at pos down the heap below the root of an H_in heap until it is not above its children.
+func (h *pathGraphHeap) siftDownHin(pos int) {
+	for {
+		least := pos
+		for child := 2 * pos; child <= 2*pos+1 && child < len(h.pq); child++ {
+			if h.pq[child].D() < h.pq[least].D() {
+				least = child
+			}
+		}
+		if least == pos {
+			return
+		}
+		h.pq[pos], h.pq[least] = h.pq[least], h.pq[pos]
+		pos = least
+	}
 }
 
 // TODO: change name
@@ -122,8 +144,8 @@ func (s set) remove(u, v, i int) {
 func (s set) exists(u, v, i int) bool {
 	if _, ok := s[u]; ok {
 		if _, ok = s[u][v]; ok {
-			pos := s[u][v][i]
-			return pos != undefinedPos
+			pos, ok := s[u][v][i]
+			return ok && pos != undefinedPos
 		}
 	}
 	return false
@@ -0,0 +1,94 @@
+package kstar
+
+import (
+	"context"
+	"sort"
+)
+
+// PathHandle is a path found by RunHandles, kept as its sidetracks on top of the search tree of A* shared by all the
+// paths of the query, so that its edges take no memory until they are asked for.
+type PathHandle struct {
+	seq     *seqNode // sidetracks, shared with the other paths of the query
+	cost    float64
+	version int // version of the search tree the path was found in
+	search  *handleSearch
+}
+
+// handleSearch is what the handles of a query share: the history of its search tree and the ends of its paths.
+type handleSearch struct {
+	tree *treeHistory
+	s, t int
+}
+
+// Cost returns the cost of the path.
+func (p *PathHandle) Cost() float64 {
+	return p.cost
+}
+
+// Sidetracks returns the edges of the path off the search tree, from S() to T().
+func (p *PathHandle) Sidetracks() []Edge {
+	return p.seq.edges()
+}
+
+// Edges returns the edges of the path as Run returns them, from T() to S().
+func (p *PathHandle) Edges() []Edge {
+	parent := func(n int) Edge { return p.search.tree.parent(n, p.version) }
+	return buildPath(p.seq.edges(), parent, p.search.s, p.search.t)
+}
+
+// Nodes returns the nodes of the path, from S() to T().
+func (p *PathHandle) Nodes() []int {
+	path := p.Edges()
+	nodes := make([]int, 0, len(path)+1)
+	nodes = append(nodes, p.search.s)
+	for i := len(path) - 1; i >= 0; i-- {
+		nodes = append(nodes, path[i].V)
+	}
+	return nodes
+}
+
+// RunHandles is RunContext returning the paths as handles, which build their edges on demand. It suits very large
+// values of k, for which the edges of every path would not fit in memory.
+func RunHandles(ctx context.Context, g Graph, k int, opts ...Option) (handles []*PathHandle, stats Stats, err error) {
+	search := &handleSearch{tree: newTreeHistory(), s: g.S(), t: g.T()}
+	stats, err = query(ctx, g, k, opts, search.tree, func(p foundPath) {
+		handles = append(handles, &PathHandle{seq: p.seq, cost: p.cost, version: search.tree.version, search: search})
+	})
+	return handles, stats, err
+}
+
+// treeHistory records the changes of the parents of the search tree, so that paths found before a resumption of A*
+// can still be built after it. Every run of A* makes a new version of the tree.
+type treeHistory struct {
+	parents map[int][]treeParent // by increasing version
+	version int
+}
+
+type treeParent struct {
+	version int
+	e       Edge
+}
+
+func newTreeHistory() *treeHistory {
+	return &treeHistory{parents: make(map[int][]treeParent)}
+}
+
+// set makes e the parent of n in the current version.
+func (th *treeHistory) set(n int, e Edge) {
+	changes := th.parents[n]
+	if last := len(changes) - 1; last >= 0 && changes[last].version == th.version {
+		changes[last].e = e
+		return
+	}
+	th.parents[n] = append(changes, treeParent{th.version, e})
+}
+
+// parent returns the parent of n in version, the zero Edge if it had none.
+func (th *treeHistory) parent(n, version int) Edge {
+	changes := th.parents[n]
+	i := sort.Search(len(changes), func(i int) bool { return changes[i].version > version })
+	if i == 0 {
+		return Edge{}
+	}
+	return changes[i-1].e
+}
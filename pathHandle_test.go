@@ -0,0 +1,68 @@
+package kstar
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestPathHandles(t *testing.T) {
+	rnd := rand.New(rand.NewSource(7))
+	changed := 0
+	for test := 0; test < 1000; test++ {
+		n := 5 + rnd.Intn(30)
+		g := randomGraph(rnd, n, n+rnd.Intn(3*n))
+		for u := 0; u < n; u++ {
+			if u != g.t {
+				// inconsistent, so that reopened nodes change the tree of paths already found
+				g.fValues[u] = float64(rnd.Intn(6))
+			}
+		}
+
+		paths := Run(g, 100)
+		handles, _, err := RunHandles(context.Background(), g, 100)
+		if err != nil || len(handles) != len(paths) {
+			t.Errorf("Graph %d %v failed! Expected %d handles, but found %d and error %v.", test, g, len(paths), len(handles), err)
+			continue
+		}
+		for i, h := range handles {
+			path := h.Edges()
+			if !reflect.DeepEqual(path, paths[i]) {
+				t.Errorf("Graph %d %v failed! Handle %d builds %v, but expected %v.", test, g, i, path, paths[i])
+			}
+			if math.Abs(h.Cost()-pathCost(g, paths[i])) > 1e-9 {
+				t.Errorf("Graph %d %v failed! Handle %d costs %f, but expected %f.", test, g, i, h.Cost(), pathCost(g, paths[i]))
+			}
+			nodes := h.Nodes()
+			if len(nodes) != len(path)+1 || nodes[0] != g.s || nodes[len(nodes)-1] != g.t {
+				t.Errorf("Graph %d %v failed! Handle %d has nodes %v for edges %v.", test, g, i, nodes, path)
+			}
+			for _, e := range path {
+				if h.search.tree.parent(e.V, h.version) != h.search.tree.parent(e.V, math.MaxInt32) {
+					changed++
+					break
+				}
+			}
+		}
+	}
+	if changed == 0 {
+		t.Error("Expected some handles to be built from an older tree.")
+	}
+}
+
+func TestPathHandlesLoopless(t *testing.T) {
+	g := newDiamondGraph()
+	g.graph[3] = map[int][]float64{0: {1}}
+	paths, _ := RunWithOptions(g, 10, WithLoopless(50))
+	handles, _, _ := RunHandles(context.Background(), g, 10, WithLoopless(50))
+	if len(handles) != len(paths) {
+		t.Fatalf("Expected %d loopless handles, but found %d.", len(paths), len(handles))
+	}
+	for i, h := range handles {
+		if !reflect.DeepEqual(h.Edges(), paths[i]) {
+			t.Errorf("Handle %d builds %v, but expected %v.", i, h.Edges(), paths[i])
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package kstar
+
+// QueryOptions are the options of a query in the JSON form of packages batch and server, whose queries embed them.
+type QueryOptions struct {
+	// Loopless only returns paths without loops, examining at most MaxExplored paths, 100 times k if 0.
+	Loopless    bool `json:"loopless,omitempty"`
+	MaxExplored int  `json:"max_explored,omitempty"`
+	// CostBound only returns paths costing at most its value.
+	CostBound *float64 `json:"cost_bound,omitempty"`
+	// ForbiddenEdges are left out of the search.
+	ForbiddenEdges []ResultEdge `json:"forbidden_edges,omitempty"`
+	// Weight multiplies the heuristic, trading exactness for speed as described in WithWeight.
+	Weight float64 `json:"weight,omitempty"`
+	// PruneDeadEnds leaves the nodes that cannot reach T out of the search, see WithDeadEndPruning.
+	PruneDeadEnds bool `json:"prune_dead_ends,omitempty"`
+	// Explain lists the sidetracks of every path in the result, see Explanation.
+	Explain bool `json:"explain,omitempty"`
+}
+
+// Options returns the Options of a query for k paths.
+func (qo QueryOptions) Options(k int) []Option {
+	var opts []Option
+	if qo.Loopless {
+		maxExplored := qo.MaxExplored
+		if maxExplored <= 0 {
+			maxExplored = 100 * k
+		}
+		opts = append(opts, WithLoopless(maxExplored))
+	}
+	if qo.CostBound != nil {
+		opts = append(opts, WithCostBound(*qo.CostBound))
+	}
+	if len(qo.ForbiddenEdges) > 0 {
+		forbidden := make([]Edge, len(qo.ForbiddenEdges))
+		for i, e := range qo.ForbiddenEdges {
+			forbidden[i] = Edge{U: e.U, V: e.V, I: e.I}
+		}
+		opts = append(opts, WithForbiddenEdges(forbidden...))
+	}
+	if qo.Weight > 1 {
+		opts = append(opts, WithWeight(qo.Weight))
+	}
+	if qo.PruneDeadEnds {
+		opts = append(opts, WithDeadEndPruning())
+	}
+	if qo.Explain {
+		opts = append(opts, WithExplanations())
+	}
+	return opts
+}
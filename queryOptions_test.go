@@ -0,0 +1,28 @@
+package kstar
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestQueryOptionsJSON(t *testing.T) {
+	var qo QueryOptions
+	err := json.Unmarshal([]byte(`{"loopless": true, "cost_bound": 6, "forbidden_edges": [{"u": 0, "v": 1, "i": 0}],
+		"weight": 1.5, "prune_dead_ends": true, "explain": true}`), &qo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := newOptions(qo.Options(3)...)
+	if !o.loopless || o.maxExplored != 300 || o.costBound == nil || *o.costBound != 6 || !o.forbidden[Edge{0, 1, 0}] ||
+		o.weight != 1.5 || !o.pruneDead || !o.explain {
+		t.Errorf("Unexpected options %+v.", o)
+	}
+
+	qo.MaxExplored = 7
+	if o := newOptions(qo.Options(3)...); o.maxExplored != 7 {
+		t.Errorf("Expected to explore 7 paths at most, but found %d.", o.maxExplored)
+	}
+	if opts := (QueryOptions{Weight: 1}).Options(3); len(opts) != 0 {
+		t.Errorf("Expected no options, but found %d.", len(opts))
+	}
+}
@@ -0,0 +1,207 @@
+package kstar
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ResultVersion is the version of the JSON schema of Result written by EncodeResult. It changes whenever a field
+// is removed or changes meaning; new fields may be added within a version.
+const ResultVersion = 1
+
+// Result is the answer to a K* query in a stable JSON schema, as written by EncodeResult and read by DecodeResult.
+type Result struct {
+	Version int `json:"version"`
+	// GraphID identifies the queried graph, such as its file name. It is free form and may be empty.
+	GraphID string       `json:"graph_id,omitempty"`
+	S       int          `json:"s"`
+	T       int          `json:"t"`
+	K       int          `json:"k"`
+	Paths   []ResultPath `json:"paths"`
+	Stats   *Stats       `json:"stats,omitempty"`
+}
+
+// ResultPath is a path of a Result, going from S to T.
+type ResultPath struct {
+	// Rank is the position of the path among the returned ones, starting at 1.
+	Rank int     `json:"rank"`
+	Cost float64 `json:"cost"`
+	// Delta is the cost of the path minus the cost of the first one. It may be negative for weighted queries.
+	Delta float64      `json:"delta"`
+	Nodes []int        `json:"nodes"`
+	Edges []ResultEdge `json:"edges"`
+	// LowerBound bounds from below the cost of the exact shortest path of the rank. Only weighted queries set it.
+	LowerBound *float64 `json:"lower_bound,omitempty"`
+	// Sidetracks are the edges of the path off the shortest path tree, see Explanation. Only queries with
+	// WithExplanations set them.
+	Sidetracks []ResultSidetrack `json:"sidetracks,omitempty"`
+}
+
+// ResultSidetrack is a sidetrack of a ResultPath, taken instead of the tree edge Instead, absent if Edge arrives at S.
+type ResultSidetrack struct {
+	Edge    ResultEdge  `json:"edge"`
+	Instead *ResultEdge `json:"instead,omitempty"`
+	Detour  float64     `json:"detour"`
+}
+
+// ResultEdge is an edge of a ResultPath, I being its index among the parallel edges from U to V.
+type ResultEdge struct {
+	U int `json:"u"`
+	V int `json:"v"`
+	I int `json:"i"`
+}
+
+// NewResult returns the Result of the paths returned by Run for g and k, turning them to go from S to T.
+// stats may be nil.
+func NewResult(graphID string, g Graph, k int, paths [][]Edge, stats *Stats) *Result {
+	res := &Result{
+		Version: ResultVersion,
+		GraphID: graphID,
+		S:       g.S(),
+		T:       g.T(),
+		K:       k,
+		Paths:   make([]ResultPath, 0, len(paths)),
+		Stats:   stats,
+	}
+	cost := costFunc(g)
+	for rank, path := range paths {
+		rp := ResultPath{Rank: rank + 1, Nodes: []int{g.S()}, Edges: make([]ResultEdge, 0, len(path))}
+		for i := len(path) - 1; i >= 0; i-- {
+			e := path[i]
+			rp.Cost += cost(e)
+			rp.Nodes = append(rp.Nodes, e.V)
+			rp.Edges = append(rp.Edges, ResultEdge{U: e.U, V: e.V, I: e.I})
+		}
+		if rank > 0 {
+			rp.Delta = rp.Cost - res.Paths[0].Cost
+		}
+		if stats != nil && rank < len(stats.LowerBounds) {
+			lb := stats.LowerBounds[rank]
+			rp.LowerBound = &lb
+		}
+		if stats != nil && rank < len(stats.Explanations) {
+			for _, st := range stats.Explanations[rank].Sidetracks {
+				rs := ResultSidetrack{Edge: ResultEdge{U: st.Edge.U, V: st.Edge.V, I: st.Edge.I}, Detour: st.Detour}
+				if st.Instead != (Edge{}) {
+					rs.Instead = &ResultEdge{U: st.Instead.U, V: st.Instead.V, I: st.Instead.I}
+				}
+				rp.Sidetracks = append(rp.Sidetracks, rs)
+			}
+		}
+		res.Paths = append(res.Paths, rp)
+	}
+	return res
+}
+
+// KstarPaths returns the paths of res as Run returns them, from T to S.
+func (res *Result) KstarPaths() [][]Edge {
+	paths := make([][]Edge, len(res.Paths))
+	for p, rp := range res.Paths {
+		paths[p] = make([]Edge, len(rp.Edges))
+		for i, e := range rp.Edges {
+			paths[p][len(rp.Edges)-1-i] = Edge{U: e.U, V: e.V, I: e.I}
+		}
+	}
+	return paths
+}
+
+// EncodeResult writes res to w as indented JSON. A zero Version is written as ResultVersion.
+func EncodeResult(w io.Writer, res *Result) error {
+	if res.Version == 0 {
+		cp := *res
+		cp.Version = ResultVersion
+		res = &cp
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "    ")
+	return enc.Encode(res)
+}
+
+// DecodeResult reads a Result written by EncodeResult, rejecting versions other than ResultVersion.
+func DecodeResult(r io.Reader) (*Result, error) {
+	res := new(Result)
+	if err := json.NewDecoder(r).Decode(res); err != nil {
+		return nil, err
+	}
+	if res.Version != ResultVersion {
+		return nil, fmt.Errorf("unsupported result version %d, expected %d", res.Version, ResultVersion)
+	}
+	return res, nil
+}
+
+// costFunc returns the cost of the edges of g, asking g directly if it is an EdgeCoster.
+func costFunc(g Graph) func(e Edge) float64 {
+	if coster, ok := g.(EdgeCoster); ok {
+		return func(e Edge) float64 {
+			return coster.EdgeCost(e.U, e.V, e.I)
+		}
+	}
+	return func(e Edge) float64 {
+		return g.Connections(e.U)[e.V][e.I]
+	}
+}
+
+// jsonStats is the JSON representation of Stats in a Result, times being in nanoseconds.
+type jsonStats struct {
+	ExpandedNodes   int   `json:"expanded_nodes"`
+	ReopenedNodes   int   `json:"reopened_nodes"`
+	PrunedEdges     int   `json:"pruned_edges,omitempty"`
+	Resumptions     int   `json:"resumptions"`
+	HinHeaps        int   `json:"hin_heaps"`
+	HinNodes        int   `json:"hin_nodes"`
+	HtHeaps         int   `json:"ht_heaps"`
+	HtNodes         int   `json:"ht_nodes"`
+	DijkstraPops    int   `json:"dijkstra_pops"`
+	AstarTimeNs     int64 `json:"astar_time_ns"`
+	PathGraphTimeNs int64 `json:"path_graph_time_ns"`
+	DijkstraTimeNs  int64 `json:"dijkstra_time_ns"`
+	PathTimeNs      int64 `json:"path_time_ns"`
+	TotalTimeNs     int64 `json:"total_time_ns"`
+}
+
+// MarshalJSON writes the stats with the field names of the Result schema.
+func (s Stats) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonStats{
+		ExpandedNodes:   s.ExpandedNodes,
+		ReopenedNodes:   s.ReopenedNodes,
+		PrunedEdges:     s.PrunedEdges,
+		Resumptions:     s.Resumptions,
+		HinHeaps:        s.HinHeaps,
+		HinNodes:        s.HinNodes,
+		HtHeaps:         s.HtHeaps,
+		HtNodes:         s.HtNodes,
+		DijkstraPops:    s.DijkstraPops,
+		AstarTimeNs:     int64(s.AstarTime),
+		PathGraphTimeNs: int64(s.PathGraphTime),
+		DijkstraTimeNs:  int64(s.DijkstraTime),
+		PathTimeNs:      int64(s.PathTime),
+		TotalTimeNs:     int64(s.TotalTime),
+	})
+}
+
+// UnmarshalJSON reads stats written by MarshalJSON.
+func (s *Stats) UnmarshalJSON(data []byte) error {
+	var js jsonStats
+	if err := json.Unmarshal(data, &js); err != nil {
+		return err
+	}
+	*s = Stats{
+		ExpandedNodes: js.ExpandedNodes,
+		ReopenedNodes: js.ReopenedNodes,
+		PrunedEdges:   js.PrunedEdges,
+		Resumptions:   js.Resumptions,
+		HinHeaps:      js.HinHeaps,
+		HinNodes:      js.HinNodes,
+		HtHeaps:       js.HtHeaps,
+		HtNodes:       js.HtNodes,
+		DijkstraPops:  js.DijkstraPops,
+		AstarTime:     time.Duration(js.AstarTimeNs),
+		PathGraphTime: time.Duration(js.PathGraphTimeNs),
+		DijkstraTime:  time.Duration(js.DijkstraTimeNs),
+		PathTime:      time.Duration(js.PathTimeNs),
+		TotalTime:     time.Duration(js.TotalTimeNs),
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package kstar
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestResultRoundTrip(t *testing.T) {
+	g := newDiamondGraph()
+	paths, stats := RunWithOptions(g, 10)
+	res := NewResult("diamond", g, 10, paths, &stats)
+
+	if res.Paths[0].Cost != 3 || res.Paths[3].Cost != 6 || res.Paths[3].Delta != 3 {
+		t.Errorf("Unexpected costs in %+v.", res.Paths)
+	}
+	if !reflect.DeepEqual(res.Paths[0].Nodes, []int{0, 1, 3}) && !reflect.DeepEqual(res.Paths[0].Nodes, []int{0, 2, 3}) {
+		t.Errorf("Expected the first path to go from S to T, but found nodes %v.", res.Paths[0].Nodes)
+	}
+	if !reflect.DeepEqual(res.KstarPaths(), paths) {
+		t.Error("KstarPaths does not give back the paths returned by Run.")
+	}
+
+	var buf bytes.Buffer
+	if err := EncodeResult(&buf, res); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"expanded_nodes": 4`) {
+		t.Errorf("Expected snake case stats in\n%s", buf.String())
+	}
+	decoded, err := DecodeResult(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, res) {
+		t.Errorf("Round trip changed the result:\n%+v\n%+v", decoded, res)
+	}
+
+	if _, err := DecodeResult(strings.NewReader(`{"version": 2, "s": 0, "t": 1, "k": 1, "paths": []}`)); err == nil {
+		t.Error("Expected an error decoding an unsupported version.")
+	}
+}
@@ -0,0 +1,66 @@
+package kstar
+
+// SearchProgress describes the state of A* when a ResumptionPolicy is consulted.
+type SearchProgress struct {
+	// ExpandedNodes and InnerEdges are the nodes expanded and the edges found between closed nodes so far.
+	ExpandedNodes, InnerEdges int
+	// ExpandedNodesAtResume and InnerEdgesAtResume are the values of the counters when A* was last interrupted.
+	ExpandedNodesAtResume, InnerEdgesAtResume int
+	// TopF is the f value of the next node A* would expand.
+	TopF float64
+	// OptimalCost is the cost of the shortest path to T().
+	OptimalCost float64
+	// DijkstraKey is the key, relative to OptimalCost, of the last path graph node popped by Dijkstra.
+	DijkstraKey float64
+}
+
+// ResumptionPolicy decides when a resumed A* is interrupted to let Dijkstra go on with the path graph.
+// A* always stops the first time T() is reached; the policy is consulted before every expansion after that,
+// once at least one node has been expanded since the last interruption. Dijkstra restarts on the updated path graph
+// after every interruption, popping the paths already returned again, so frequent interruptions trade expansions for
+// pops, see Stats.DijkstraPops.
+type ResumptionPolicy interface {
+
+	// ShouldStop reports whether A* must be interrupted.
+	ShouldStop(p SearchProgress) bool
+}
+
+// DoublingPolicy interrupts A* once both the inner edges and the expanded nodes have doubled since the last interruption.
+// It is the default policy, as described in the K* paper.
+type DoublingPolicy struct{}
+
+// ShouldStop reports whether both the inner edges and the expanded nodes have doubled.
+func (DoublingPolicy) ShouldStop(p SearchProgress) bool {
+	innerEdgesDoubled := p.InnerEdgesAtResume == 0 && float32(p.InnerEdges+2)/float32(p.InnerEdgesAtResume+1) >= 2 || float32(p.InnerEdges)/float32(p.InnerEdgesAtResume) >= 2
+	expandedNodesDoubled := p.ExpandedNodesAtResume == 0 && float32(p.ExpandedNodes+2)/float32(p.ExpandedNodesAtResume+1) >= 2 || float32(p.ExpandedNodes)/float32(p.ExpandedNodesAtResume) >= 2
+	return innerEdgesDoubled && expandedNodesDoubled
+}
+
+// FixedBatchPolicy interrupts A* after every Expansions expanded nodes.
+type FixedBatchPolicy struct {
+	Expansions int
+}
+
+// ShouldStop reports whether Expansions nodes have been expanded since the last interruption.
+func (p FixedBatchPolicy) ShouldStop(sp SearchProgress) bool {
+	return sp.ExpandedNodes-sp.ExpandedNodesAtResume >= p.Expansions
+}
+
+// CostHorizonPolicy interrupts A* once the f value of the next node exceeds the cost of the paths Dijkstra is
+// currently enumerating by more than Delta. A bigger Delta means fewer, longer resumptions.
+type CostHorizonPolicy struct {
+	Delta float64
+}
+
+// ShouldStop reports whether the next f value exceeds OptimalCost + DijkstraKey + Delta.
+func (p CostHorizonPolicy) ShouldStop(sp SearchProgress) bool {
+	return sp.TopF > sp.OptimalCost+sp.DijkstraKey+p.Delta
+}
+
+// ExhaustivePolicy never interrupts A*, which runs to exhaustion the first time it is resumed.
+type ExhaustivePolicy struct{}
+
+// ShouldStop always returns false.
+func (ExhaustivePolicy) ShouldStop(p SearchProgress) bool {
+	return false
+}
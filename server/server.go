@@ -0,0 +1,179 @@
+// Package server answers k shortest paths queries over HTTP on graphs loaded at startup.
+//
+// POST /v1/paths takes a JSON Request and answers with a kstar.Result, or with an ErrorResponse and a 4xx or 5xx
+// status. GET /healthz answers 200 while the server is up. Queries run concurrently, sharing the graphs, which are
+// never modified.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/jcasado94/kstar"
+)
+
+// Request is the body of a POST /v1/paths query.
+type Request struct {
+	// Graph is the name of the graph to query. It may be omitted if the server holds a single graph.
+	Graph string `json:"graph,omitempty"`
+	// S and T are the departure and arrival nodes, the ones of the graph if omitted.
+	S *int `json:"s,omitempty"`
+	T *int `json:"t,omitempty"`
+	K int  `json:"k"`
+	// QueryOptions configure the query; loopless ones examine at most Config.MaxExplored paths if MaxExplored is 0.
+	kstar.QueryOptions
+	// TimeoutMs is the time the query may run, in milliseconds. It is capped by Config.Timeout.
+	TimeoutMs int `json:"timeout_ms,omitempty"`
+}
+
+// ErrorResponse is the body of a failed query.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// Config limits the queries of a Server. Zero values take the defaults.
+type Config struct {
+	// Timeout is the longest a query may run, 30 seconds by default.
+	Timeout time.Duration
+	// MaxK is the largest k a query may ask for, 10000 by default.
+	MaxK int
+	// MaxExplored is the number of paths a loopless query examines at most, 100 times its k by default.
+	MaxExplored int
+}
+
+// Server answers queries on a set of named graphs. It implements http.Handler.
+type Server struct {
+	graphs map[string]*kstar.AdjacencyGraph
+	cfg    Config
+	mux    *http.ServeMux
+}
+
+// New returns a Server answering queries on graphs, which must not be modified while it runs.
+func New(graphs map[string]*kstar.AdjacencyGraph, cfg Config) *Server {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	if cfg.MaxK <= 0 {
+		cfg.MaxK = 10000
+	}
+	s := &Server{graphs: graphs, cfg: cfg, mux: http.NewServeMux()}
+	s.mux.HandleFunc("/v1/paths", s.handlePaths)
+	s.mux.HandleFunc("/healthz", s.handleHealth)
+	return s
+}
+
+// ServeHTTP dispatches a request to its handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Graphs returns the names of the graphs of s in ascending order.
+func (s *Server) Graphs() []string {
+	names := make([]string, 0, len(s.graphs))
+	for name := range s.graphs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, "ok")
+}
+
+func (s *Server) handlePaths(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, errors.New("only POST is allowed"))
+		return
+	}
+	var req Request
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request: %v", err))
+		return
+	}
+
+	name, g, err := s.graph(req)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	g, opts, err := s.query(req, g)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	timeout := s.cfg.Timeout
+	if t := time.Duration(req.TimeoutMs) * time.Millisecond; t > 0 && t < timeout {
+		timeout = t
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+	paths, stats, err := kstar.RunContext(ctx, g, req.K, opts...)
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		writeError(w, http.StatusGatewayTimeout, fmt.Errorf("query timed out after %v", timeout))
+		return
+	case err != nil:
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	kstar.EncodeResult(w, kstar.NewResult(name, g, req.K, paths, &stats))
+}
+
+// graph returns the graph asked for by req and its name.
+func (s *Server) graph(req Request) (string, *kstar.AdjacencyGraph, error) {
+	if req.Graph == "" && len(s.graphs) == 1 {
+		for name, g := range s.graphs {
+			return name, g, nil
+		}
+	}
+	g, ok := s.graphs[req.Graph]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown graph %q", req.Graph)
+	}
+	return req.Graph, g, nil
+}
+
+// query validates req and returns the graph rerouted to its endpoints and the options of its query.
+func (s *Server) query(req Request, g *kstar.AdjacencyGraph) (*kstar.AdjacencyGraph, []kstar.Option, error) {
+	if req.K < 1 || req.K > s.cfg.MaxK {
+		return nil, nil, fmt.Errorf("k must be in [1, %d]", s.cfg.MaxK)
+	}
+	src, dst := g.S(), g.T()
+	if req.S != nil {
+		src = *req.S
+	}
+	if req.T != nil {
+		dst = *req.T
+	}
+	inGraph := func(n int) bool {
+		return n >= 0 && n < g.NumNodes()
+	}
+	if !inGraph(src) || !inGraph(dst) {
+		return nil, nil, fmt.Errorf("s %d or t %d out of range [0, %d)", src, dst, g.NumNodes())
+	}
+
+	if req.MaxExplored <= 0 {
+		req.MaxExplored = s.cfg.MaxExplored
+	}
+	opts := req.Options(req.K)
+	return g.Reroute(src, dst), opts, nil
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+}
@@ -0,0 +1,146 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jcasado94/kstar"
+	"github.com/jcasado94/kstar/graphio"
+)
+
+func newTestServer(t *testing.T) *Server {
+	graphs := make(map[string]*kstar.AdjacencyGraph)
+	for _, name := range []string{"5.1", "5.2"} {
+		g, err := graphio.ReadFile("../datasets/graph/" + name + ".graph")
+		if err != nil {
+			t.Fatal(err)
+		}
+		graphs[name] = g
+	}
+	return New(graphs, Config{})
+}
+
+func post(srv http.Handler, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/paths", strings.NewReader(body)))
+	return rec
+}
+
+func TestPaths(t *testing.T) {
+	srv := newTestServer(t)
+	rec := post(srv, `{"graph": "5.1", "k": 3}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, but found %d: %s", rec.Code, rec.Body)
+	}
+	res, err := kstar.DecodeResult(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.GraphID != "5.1" || len(res.Paths) != 2 || res.Paths[0].Cost != 5 || res.Stats == nil {
+		t.Errorf("Unexpected result %+v.", res)
+	}
+
+	rec = post(srv, `{"graph": "5.1", "s": 1, "t": 4, "k": 10, "cost_bound": 6, "forbidden_edges": [{"u": 1, "v": 2, "i": 0}]}`)
+	res, err = kstar.DecodeResult(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.S != 1 || len(res.Paths) != 1 || res.Paths[0].Cost != 6 {
+		t.Errorf("Expected a single path from 1 costing 6, but found %+v.", res.Paths)
+	}
+
+	rec = post(srv, `{"graph": "5.2", "k": 3, "weight": 2}`)
+	res, err = kstar.DecodeResult(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rp := range res.Paths {
+		if rp.LowerBound == nil || *rp.LowerBound > rp.Cost || rp.Cost > 2**rp.LowerBound {
+			t.Errorf("Expected a lower bound within a factor 2 of the cost of %+v.", rp)
+		}
+	}
+}
+
+func TestPathsErrors(t *testing.T) {
+	srv := newTestServer(t)
+	tests := []struct {
+		body   string
+		status int
+	}{
+		{`{"graph": "none", "k": 3}`, http.StatusNotFound},
+		{`{"k": 3}`, http.StatusNotFound},
+		{`{"graph": "5.1", "k": 0}`, http.StatusBadRequest},
+		{`{"graph": "5.1", "k": 3, "t": 99}`, http.StatusBadRequest},
+		{`{"graph": "5.1", "k": 3, "unknown": 1}`, http.StatusBadRequest},
+		{`{"graph": "5.1"`, http.StatusBadRequest},
+	}
+	for _, test := range tests {
+		rec := post(srv, test.body)
+		var errResp ErrorResponse
+		if rec.Code != test.status || json.NewDecoder(rec.Body).Decode(&errResp) != nil || errResp.Error == "" {
+			t.Errorf("Expected status %d with an error for %s, but found %d.", test.status, test.body, rec.Code)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/paths", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405 for a GET, but found %d.", rec.Code)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/v1/paths", strings.NewReader(`{"graph": "5.1", "k": 3}`))
+	srv.ServeHTTP(rec, req.WithContext(ctx))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503 for a cancelled request, but found %d.", rec.Code)
+	}
+}
+
+func TestHealth(t *testing.T) {
+	ts := httptest.NewServer(newTestServer(t))
+	defer ts.Close()
+	resp, err := http.Get(ts.URL + "/healthz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, but found %d.", resp.StatusCode)
+	}
+}
+
+func TestConcurrentQueries(t *testing.T) {
+	ts := httptest.NewServer(newTestServer(t))
+	defer ts.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			body := fmt.Sprintf(`{"graph": "5.2", "k": %d}`, 1+i%5)
+			resp, err := http.Post(ts.URL+"/v1/paths", "application/json", bytes.NewBufferString(body))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer resp.Body.Close()
+			res, err := kstar.DecodeResult(resp.Body)
+			if err != nil {
+				t.Error(err)
+			} else if len(res.Paths) != 1+i%5 {
+				t.Errorf("Expected %d paths, but found %d.", 1+i%5, len(res.Paths))
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,92 @@
+package kstar
+
+import (
+	"context"
+	"time"
+)
+
+// Session answers k shortest paths queries from the departure node of a graph to successive arrival nodes. The A*
+// search tree and the path graph are kept between queries: a target already reached by the search is answered from
+// them, any other one extends the search until it is reached.
+//
+// Since the heuristic of a Graph estimates costs to its T() only, the search of a Session ignores FValue. A Session
+// is not safe for concurrent use.
+type Session struct {
+	g  *sessionGraph
+	ks kstar
+}
+
+// sessionGraph is the Graph searched by a Session, arriving at the target of its current query.
+type sessionGraph struct {
+	Graph
+	t int
+}
+
+func (sg *sessionGraph) T() int { return sg.t }
+
+func (sg *sessionGraph) FValue(n int) float64 { return 0 }
+
+// NewSession returns a Session departing from g.S(), configured by opts for all of its queries.
+func NewSession(g Graph, opts ...Option) *Session {
+	sg := &sessionGraph{Graph: g, t: g.T()}
+	graph := WrapGraph(sg, g)
+	s := &Session{g: sg, ks: newKstar(&graph, newPathGraph())}
+	newOptions(opts...).apply(&s.ks)
+	return s
+}
+
+// Paths returns the k shortest paths from S() to t, along with statistics about the work done for this query. It is
+// interrupted when ctx is done, in which case it returns the paths found so far and ctx.Err(); the Session can still
+// be queried afterwards.
+func (s *Session) Paths(ctx context.Context, t, k int) (paths [][]Edge, stats Stats, err error) {
+	start := time.Now()
+	ks := &s.ks
+	s.g.t = t
+	ks.ctx, ks.as.ctx = ctx, ctx
+	paths = make([][]Edge, 0)
+	ks.emit = func(p foundPath) {
+		paths = append(paths, p.path)
+	}
+	ks.emitted, ks.err, ks.lastKey, ks.stats = 0, nil, 0, Stats{}
+	ks.d.pops = 0
+	expanded, reopened, pruned := ks.as.c.expandedNodes, ks.as.reopenedNodes, ks.as.prunedEdges
+
+	if ks.retarget() {
+		ks.search(k)
+	}
+	ks.dumpPathGraph()
+	ks.collectStats()
+	ks.stats.ExpandedNodes -= expanded
+	ks.stats.ReopenedNodes -= reopened
+	ks.stats.PrunedEdges -= pruned
+	ks.stats.TotalTime = time.Since(start)
+	return paths, ks.stats, ks.err
+}
+
+// retarget prepares the path graph and Dijkstra for a query to T(), running A* until T() is reached unless it
+// already was. It returns false if T() cannot be reached or the query was interrupted.
+func (ks *kstar) retarget() bool {
+	t := ks.g.T()
+	if !ks.as.closed(t) {
+		if ks.asExhausted {
+			return false
+		}
+		ks.as.c.start = true
+		return ks.startAstar()
+	}
+
+	// an interrupted query may have left A* looking for another target
+	ks.as.c.start = false
+	if ks.stale {
+		ks.rebuild(nil)
+		return true
+	}
+	// the H_T heaps do not depend on T(), only R has to point to the one of the new target
+	ks.pg.r.tHt = ks.pg.ht[t]
+	if ks.checks {
+		ks.pg.checkPathGraph(ks.as)
+	}
+	ks.d = newDijkstra(&ks.pg.r)
+	ks.d.obs = ks.obs
+	return true
+}
@@ -0,0 +1,68 @@
+package kstar
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestSessionAgainstBruteForce(t *testing.T) {
+	rnd := rand.New(rand.NewSource(2))
+	for test := 0; test < 100; test++ {
+		n := 2 + rnd.Intn(6)
+		g := randomGraph(rnd, n, rnd.Intn(3*n))
+
+		s := NewSession(g)
+		for _, target := range rnd.Perm(n) {
+			g.t = target
+			paths, _, err := s.Paths(context.Background(), target, 6)
+			if err != nil {
+				t.Errorf("Graph %d %v failed! Paths to %d returned %v.", test, g, target, err)
+				continue
+			}
+			checkBruteForce(t, fmt.Sprintf("Graph %d %v to %d", test, g, target), g, 6, paths)
+		}
+	}
+}
+
+func TestSessionSharesSearch(t *testing.T) {
+	g := newGridGraph(8, 8)
+	s := NewSession(g)
+	total := 0
+	for _, target := range []int{g.T(), 9, 35, g.T(), 60} {
+		paths, stats, err := s.Paths(context.Background(), target, 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := Run(g.Reroute(g.S(), target), 5)
+		if len(paths) != len(expected) {
+			t.Fatalf("Expected %d paths to %d, but found %d.", len(expected), target, len(paths))
+		}
+		for i := range paths {
+			if pathCost(g, paths[i]) != pathCost(g, expected[i]) {
+				t.Errorf("Path %d to %d costs %f, but expected %f.", i, target, pathCost(g, paths[i]), pathCost(g, expected[i]))
+			}
+		}
+		total += stats.ExpandedNodes
+	}
+	if total > 2*g.NumNodes() {
+		t.Errorf("Expected the queries to share the search, but they expanded %d nodes.", total)
+	}
+}
+
+func TestSessionContext(t *testing.T) {
+	g := newGridGraph(6, 6)
+	s := NewSession(g)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if paths, _, err := s.Paths(ctx, g.T(), 3); err != context.Canceled || len(paths) != 0 {
+		t.Errorf("Expected no paths and a cancellation error, but found %d paths and %v.", len(paths), err)
+	}
+	if paths, _, err := s.Paths(context.Background(), g.T(), 3); err != nil || len(paths) != 3 {
+		t.Errorf("Expected 3 paths and no error, but found %d paths and %v.", len(paths), err)
+	}
+	if paths, _, err := s.Paths(context.Background(), g.NumNodes(), 3); err != nil || len(paths) != 0 {
+		t.Errorf("Expected no paths to a node out of the graph, but found %d paths and %v.", len(paths), err)
+	}
+}
@@ -0,0 +1,35 @@
+package kstar
+
+import "time"
+
+// Stats reports what K* did to answer a query.
+type Stats struct {
+	// ExpandedNodes is the number of A* expansions, reopenings included.
+	ExpandedNodes int
+	// ReopenedNodes is the number of closed nodes A* expanded again because the heuristic is not consistent.
+	ReopenedNodes int
+	// PrunedEdges is the number of edges into nodes that cannot reach T() left out by WithDeadEndPruning.
+	PrunedEdges int
+	// Resumptions is the number of times A* was resumed after reaching T().
+	Resumptions int
+
+	// HinHeaps and HinNodes are the number of H_in heaps and the sidetrack edges stored in them.
+	HinHeaps, HinNodes int
+	// HtHeaps and HtNodes are the number of H_T heaps and the nodes stored in them.
+	HtHeaps, HtNodes int
+	// DijkstraPops is the number of path graph nodes popped by Dijkstra.
+	DijkstraPops int
+
+	// AstarTime, PathGraphTime, DijkstraTime and PathTime are the time spent running A*, updating the path graph,
+	// running Dijkstra on the path graph and building the returned paths.
+	AstarTime, PathGraphTime, DijkstraTime, PathTime time.Duration
+	// TotalTime is the wall time of the whole query.
+	TotalTime time.Duration
+
+	// LowerBounds holds, for every returned path, a lower bound of the cost of the exact shortest path of its rank.
+	// It is only set by weighted queries, see WithWeight, and is written in the paths of a Result.
+	LowerBounds []float64
+	// Explanations describes every returned path. It is only set by queries with WithExplanations, and its sidetracks
+	// are written in the paths of a Result.
+	Explanations []Explanation
+}
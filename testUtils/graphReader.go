@@ -37,7 +37,7 @@ func (tg TestGraph) Nodes() []int {
 	return nodes
 }
 
-// Connections returns the problem connections
+// Connections returns the problem connections. The map is the graph's own, K* only reads it.
 func (tg TestGraph) Connections(n int) map[int][]float64 {
 	return tg.graph[n]
 }